@@ -43,10 +43,11 @@ const (
 	URLTypeWeb
 	// UrlTypeStatic defines the url to the static assets like css, js or theme images
 	URLTypeStatic
-	// UrlTypeLink hmmm
-	// UrlTypeLink
 	// UrlTypeMedia defines the ULR type for generating URLs to product photos
 	URLTypeMedia
+	// URLTypeLink defines the URL type for links to pages and routes. It may
+	// contain the store code.
+	URLTypeLink
 )
 
 type (
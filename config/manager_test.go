@@ -19,6 +19,7 @@ import (
 
 	"github.com/corestoreio/csfw/config"
 	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/stretchr/testify/assert"
 )
@@ -81,3 +82,16 @@ func TestApplyCoreConfigData(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestApplyCoreConfigDataMock(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	mock.ExpectQuery("FROM `" + config.TableCollection.Name(config.TableIndexCoreConfigData) + "` AS `main_table`").
+		WillReturnRows(csdbtest.NewRows("config_id", "scope", "scope_id", "path", "value").
+			AddRow(1, "default", 0, "web/seo/use_rewrites", "1").
+			AddRow(2, "websites", 1, "web/unsecure/base_url", nil))
+
+	m := config.NewManager()
+	assert.NoError(t, m.ApplyCoreConfigData(dbr.NewConnection(db, nil).NewSession(nil)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -15,11 +15,135 @@
 package directory
 
 import (
+	"errors"
+	"sync"
+
 	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/juju/errgo"
 	"golang.org/x/text/language"
 )
 
+// TableCurrencyRateName name of the table which contains the currency rates.
+// Change it if your tables have a prefix.
+var TableCurrencyRateName = "directory_currency_rate"
+
+// DefaultRates package wide rates table used by Currency when no other
+// Rates have been provided. Fill it with Rates.Load() or Rates.Set().
+var DefaultRates = NewRates()
+
+// ErrCurrencyRateNotFound the rate between two currencies is not available
+var ErrCurrencyRateNotFound = errors.New("Currency rate not found")
+
+type (
+	// Currency represents a currency with its conversion rates to other
+	// currencies. You should use NewCurrency() to create a new type.
+	Currency struct {
+		// ISO contains the 3-letter ISO 4217 currency code.
+		ISO language.Currency
+		// Rates contains the conversion rates. If nil DefaultRates will be used.
+		Rates *Rates
+	}
+
+	// Rates contains currency conversion rates mostly loaded from the table
+	// directory_currency_rate. Rates is safe for concurrent use.
+	Rates struct {
+		mu sync.RWMutex
+		// r first key currency_from, second key currency_to
+		r map[string]map[string]float64
+	}
+
+	// currencyRateRow represents one row in directory_currency_rate
+	currencyRateRow struct {
+		From string  `db:"currency_from"`
+		To   string  `db:"currency_to"`
+		Rate float64 `db:"rate"`
+	}
+)
+
 // BaseCurrencyCode retrieves application base currency code
 func BaseCurrencyCode(cr config.Reader) (language.Currency, error) {
 	return language.ParseCurrency(cr.GetString(config.Path(PathCurrencyBase)))
 }
+
+// NewCurrency creates a new Currency. Rates can be nil.
+func NewCurrency(iso language.Currency, r *Rates) Currency {
+	return Currency{
+		ISO:   iso,
+		Rates: r,
+	}
+}
+
+// Rate returns the conversion rate from this currency to currency to.
+func (c Currency) Rate(to language.Currency) (float64, error) {
+	r := c.Rates
+	if r == nil {
+		r = DefaultRates
+	}
+	return r.Rate(c.ISO, to)
+}
+
+// Convert converts a price which must be in this currency into the currency to.
+func (c Currency) Convert(price money.Currency, to language.Currency) (money.Currency, error) {
+	rate, err := c.Rate(to)
+	if err != nil {
+		return price, err
+	}
+	if rate == 1 {
+		return price, nil
+	}
+	return price.Mulf(rate), nil
+}
+
+// NewRates creates a new empty rates table.
+func NewRates() *Rates {
+	return &Rates{
+		r: make(map[string]map[string]float64),
+	}
+}
+
+// Set adds or replaces the conversion rate between from and to.
+func (r *Rates) Set(from, to language.Currency, rate float64) *Rates {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.set(from.String(), to.String(), rate)
+	return r
+}
+
+func (r *Rates) set(from, to string, rate float64) {
+	if _, ok := r.r[from]; !ok {
+		r.r[from] = make(map[string]float64)
+	}
+	r.r[from][to] = rate
+}
+
+// Rate returns the conversion rate between from and to. Equal currencies
+// have always the rate 1. Returns ErrCurrencyRateNotFound if the rate is
+// not available.
+func (r *Rates) Rate(from, to language.Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if rate, ok := r.r[from.String()][to.String()]; ok && rate > 0 {
+		return rate, nil
+	}
+	return 0, ErrCurrencyRateNotFound
+}
+
+// Load replaces all rates with the rows from the table TableCurrencyRateName.
+func (r *Rates) Load(dbrSess dbr.SessionRunner) error {
+	var rows []*currencyRateRow
+	if _, err := dbrSess.Select("currency_from", "currency_to", "rate").From(TableCurrencyRateName).LoadStructs(&rows); err != nil {
+		return errgo.Mask(err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.r = make(map[string]map[string]float64, len(rows))
+	for _, row := range rows {
+		r.set(row.From, row.To, row.Rate)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package directory_test
+
+import (
+	"testing"
+
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestCurrencyConvert(t *testing.T) {
+	eur := language.MustParseCurrency("EUR")
+	chf := language.MustParseCurrency("CHF")
+	usd := language.MustParseCurrency("USD")
+
+	r := directory.NewRates().Set(eur, chf, 1.5).Set(eur, usd, 0)
+	c := directory.NewCurrency(eur, r)
+
+	rate, err := c.Rate(eur)
+	assert.NoError(t, err)
+	assert.Exactly(t, 1.0, rate)
+
+	p, err := c.Convert(money.New().Setf(10), chf)
+	assert.NoError(t, err)
+	assert.Exactly(t, 15.0, p.Getf())
+
+	_, err = c.Convert(money.New().Setf(10), usd)
+	assert.EqualError(t, err, directory.ErrCurrencyRateNotFound.Error())
+
+	_, err = directory.NewCurrency(chf, r).Rate(eur)
+	assert.EqualError(t, err, directory.ErrCurrencyRateNotFound.Error())
+}
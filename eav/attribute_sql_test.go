@@ -0,0 +1,88 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eav_test
+
+import (
+	"testing"
+
+	"github.com/corestoreio/csfw/eav"
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/stretchr/testify/assert"
+)
+
+type testAttributeTabler struct {
+	website *csdb.TableStructure
+}
+
+func (testAttributeTabler) TableAdditionalAttribute() (*csdb.TableStructure, error) {
+	return csdb.NewTableStructure(
+		"customer_eav_attribute",
+		[]string{"attribute_id"},
+		[]string{"is_visible", "input_filter", "multiline_count", "validate_rules", "is_system", "sort_order", "data_model"},
+	), nil
+}
+
+func (a testAttributeTabler) TableEavWebsite() (*csdb.TableStructure, error) {
+	return a.website, nil
+}
+
+type testAttribute struct {
+	AttributeID   int64  `db:"attribute_id"`
+	AttributeCode string `db:"attribute_code"`
+}
+
+func TestGetAttributeSelectSql(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	sess := dbr.NewConnection(db, nil).NewSession(nil)
+
+	aat := testAttributeTabler{
+		website: csdb.NewTableStructure(
+			"customer_eav_attribute_website",
+			[]string{"attribute_id", "website_id"},
+			[]string{"is_visible", "is_required", "default_value", "multiline_count"},
+		),
+	}
+	sb, err := eav.GetAttributeSelectSql(sess, aat, 1, 4)
+	assert.NoError(t, err)
+
+	sql, args := sb.ToSql()
+	assert.Exactly(t, []interface{}{int64(1), int64(4)}, args)
+	assert.Contains(t, sql, "FROM `eav_attribute` AS `main_table`")
+	assert.Contains(t, sql, "INNER JOIN `customer_eav_attribute` AS `additional_table` ON (`additional_table`.`attribute_id` = `main_table`.`attribute_id`) AND (`main_table`.`entity_type_id` = ?)")
+	assert.Contains(t, sql, "LEFT JOIN `customer_eav_attribute_website` AS `scope_table` ON (`scope_table`.`attribute_id` = `main_table`.`attribute_id`) AND (`scope_table`.`website_id` = ?)")
+	assert.Contains(t, sql, "IFNULL(`scope_table`.`is_visible`, `additional_table`.`is_visible`) AS `is_visible`")
+	assert.Contains(t, sql, "IFNULL(`scope_table`.`is_required`, `main_table`.`is_required`) AS `is_required`")
+	assert.Contains(t, sql, "IFNULL(`scope_table`.`default_value`, `main_table`.`default_value`) AS `default_value`")
+	assert.NotContains(t, sql, "`additional_table`.`is_visible`, ")
+	assert.NotContains(t, sql, "`main_table`.`is_required`, ")
+
+	mock.ExpectQuery("FROM `eav_attribute` AS `main_table`").
+		WillReturnRows(csdbtest.NewRows("attribute_id", "attribute_code").AddRow(1, "email").AddRow(2, "firstname"))
+	var attrs []*testAttribute
+	n, err := sb.LoadStructs(&attrs)
+	assert.NoError(t, err)
+	assert.Exactly(t, 2, n)
+	assert.Exactly(t, "firstname", attrs[1].AttributeCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Contains(t, mock.Statements()[0].SQL, "`main_table`.`entity_type_id` = 1")
+	assert.Contains(t, mock.Statements()[0].SQL, "`scope_table`.`website_id` = 4")
+
+	aat.website = csdb.NewTableStructure("customer_eav_attribute_website", nil, []string{"not_existent"})
+	_, err = eav.GetAttributeSelectSql(sess, aat, 1, 4)
+	assert.EqualError(t, err, "Cannot find column name customer_eav_attribute_website.not_existent neither in table eav_attribute nor in customer_eav_attribute.")
+}
@@ -18,6 +18,7 @@ import (
 	"database/sql"
 	"errors"
 	"os"
+	"strings"
 
 	"github.com/corestoreio/csfw/storage/dbr"
 	_ "github.com/go-sql-driver/mysql"
@@ -29,6 +30,8 @@ const (
 	EnvDSN string = "CS_DSN"
 	// EnvDSNTest test env DSN
 	EnvDSNTest string = "CS_DSN_TEST"
+	// EnvDSNReplicas optional comma separated list of DSNs of read replicas
+	EnvDSNReplicas string = "CS_DSN_REPLICAS"
 )
 
 var (
@@ -54,6 +57,24 @@ func GetDSNTest() (string, error) {
 	return getDSN(EnvDSNTest, ErrDSNTestNotFound)
 }
 
+// GetDSNReplicas returns the DSNs of the read replicas from env. The DSNs
+// are separated by a comma. Returns nil if the env var is not set.
+func GetDSNReplicas() []string {
+	var dsns []string
+	for _, dsn := range strings.Split(os.Getenv(EnvDSNReplicas), ",") {
+		if dsn = strings.TrimSpace(dsn); dsn != "" {
+			dsns = append(dsns, dsn)
+		}
+	}
+	return dsns
+}
+
+// Connect opens the primary database from env CS_DSN and the read replicas
+// from env CS_DSN_REPLICAS. SELECT statements of the dbr.Connection will be
+// routed to the replicas. Connect does not start the health check of the
+// replicas, the caller must run dbrConn.StartReplicaHealthCheck() to stop
+// routing to failing replicas. The caller must also close the replicas,
+// see dbrConn.Replicas(). On error all opened databases will be closed.
 func Connect() (*sql.DB, *dbr.Connection, error) {
 	dsn, err := GetDSN()
 	if err != nil {
@@ -64,6 +85,17 @@ func Connect() (*sql.DB, *dbr.Connection, error) {
 		return nil, nil, errgo.Mask(err)
 	}
 	dbrConn := dbr.NewConnection(db, nil)
+	for _, rdsn := range GetDSNReplicas() {
+		rdb, err := sql.Open("mysql", rdsn)
+		if err != nil {
+			for _, r := range dbrConn.Replicas() {
+				r.Close()
+			}
+			db.Close()
+			return nil, nil, errgo.Mask(err)
+		}
+		dbrConn.AddReplicas(rdb)
+	}
 	return db, dbrConn, nil
 }
 
@@ -50,3 +50,25 @@ func TestGetDSN(t *testing.T) {
 		assert.Equal(t, test.err, aErr)
 	}
 }
+
+func TestGetDSNReplicas(t *testing.T) {
+	defer os.Setenv(EnvDSNReplicas, os.Getenv(EnvDSNReplicas))
+
+	os.Setenv(EnvDSNReplicas, "")
+	assert.Nil(t, GetDSNReplicas())
+
+	os.Setenv(EnvDSNReplicas, "user:pw@tcp(r1:3306)/db, user:pw@tcp(r2:3306)/db,")
+	assert.Equal(t, []string{"user:pw@tcp(r1:3306)/db", "user:pw@tcp(r2:3306)/db"}, GetDSNReplicas())
+}
+
+func TestConnectReplicaError(t *testing.T) {
+	defer os.Setenv(EnvDSN, os.Getenv(EnvDSN))
+	defer os.Setenv(EnvDSNReplicas, os.Getenv(EnvDSNReplicas))
+
+	os.Setenv(EnvDSN, "user:pw@tcp(localhost:3306)/db")
+	os.Setenv(EnvDSNReplicas, "user:pw@tcp(r1:3306)/db,invalid DSN")
+	db, dbrConn, err := Connect()
+	assert.Error(t, err)
+	assert.Nil(t, db)
+	assert.Nil(t, dbrConn)
+}
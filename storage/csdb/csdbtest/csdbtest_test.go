@@ -0,0 +1,156 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStore struct {
+	StoreID   int64 `db:"store_id"`
+	Code      dbr.NullString
+	WebsiteID int64 `db:"website_id"`
+	Name      string
+}
+
+func TestMockExpectations(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	sess := dbr.NewConnection(db, nil).NewSession(nil)
+
+	mock.ExpectQuery("SELECT store_id, code FROM store WHERE (website_id = 1)").
+		WillReturnRows(csdbtest.NewRows("store_id", "code").AddRow(1, "de").AddRow(2, nil))
+	mock.ExpectExec("UPDATE store SET `name` = 'DE'").WillReturnResult(0, 1)
+	mock.ExpectExec("DELETE FROM store").WillReturnError(errors.New("locked"))
+
+	var stores []*testStore
+	n, err := sess.Select("store_id", "code").From("store").Where("website_id = ?", 1).LoadStructs(&stores)
+	assert.NoError(t, err)
+	assert.Exactly(t, 2, n)
+	assert.Exactly(t, "de", stores[0].Code.String)
+	assert.False(t, stores[1].Code.Valid)
+
+	res, err := sess.Update("store").Set("name", "DE").Where("store_id = ?", 1).Exec()
+	assert.NoError(t, err)
+	ra, _ := res.RowsAffected()
+	assert.Exactly(t, int64(1), ra)
+
+	_, err = sess.DeleteFrom("store").Exec()
+	assert.EqualError(t, err, "locked")
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = sess.InsertInto("store").Columns("code").Values("xx").Exec()
+	assert.Error(t, err)
+	assert.Len(t, mock.Statements(), 4)
+	assert.Exactly(t, "SELECT store_id, code FROM store WHERE (website_id = 1)", mock.Statements()[0].SQL)
+}
+
+func TestMockOrder(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	sess := dbr.NewConnection(db, nil).EnableStmtCache(0).NewSession(nil)
+
+	mock.ExpectExec("INSERT INTO sales_order").WithArgs("a", 1)
+	mock.ExpectExec("UPDATE cataloginventory_stock_item")
+
+	_, err := sess.Update("cataloginventory_stock_item").Set("qty", dbr.Expr("qty - 1")).Exec()
+	assert.Error(t, err)
+
+	tx, err := sess.Begin()
+	assert.NoError(t, err)
+	_, err = tx.InsertInto("sales_order").Columns("increment_id", "store_id").Values("a", 1).Exec()
+	assert.NoError(t, err)
+	_, err = tx.Update("cataloginventory_stock_item").Set("qty", dbr.Expr("qty - 1")).Exec()
+	assert.NoError(t, err)
+	assert.NoError(t, tx.Commit())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	var sqls []string
+	for _, s := range mock.Statements() {
+		sqls = append(sqls, s.SQL)
+	}
+	assert.Exactly(t, []string{
+		"UPDATE cataloginventory_stock_item SET `qty` = qty - 1",
+		"BEGIN",
+		"INSERT INTO sales_order (`increment_id`,`store_id`) VALUES (?,?)",
+		"UPDATE cataloginventory_stock_item SET `qty` = qty - 1",
+		"COMMIT",
+	}, sqls)
+}
+
+const testDump = "CREATE TABLE `store` (\n" +
+	"  `store_id` smallint(5) unsigned NOT NULL AUTO_INCREMENT COMMENT 'Store Id',\n" +
+	"  `code` varchar(32) DEFAULT NULL COMMENT 'Code',\n" +
+	"  `website_id` smallint(5) unsigned NOT NULL DEFAULT '0' COMMENT 'Website Id',\n" +
+	"  `name` varchar(255) NOT NULL COMMENT 'Store Name',\n" +
+	"  PRIMARY KEY (`store_id`),\n" +
+	"  UNIQUE KEY `UNQ_STORE_CODE` (`code`)\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=10 DEFAULT CHARSET=utf8 COMMENT='Stores';\n" +
+	"INSERT INTO `store` VALUES (0,'admin',0,'Admin'),(1,NULL,1,'It\\'s, (DE)');\n" +
+	"CREATE TABLE `other` (\n" +
+	"  `id` int\n" +
+	");\n"
+
+func TestMockFixtures(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	sess := dbr.NewConnection(db, nil).NewSession(nil)
+
+	assert.Error(t, mock.LoadDump(strings.NewReader(testDump), "store", "missing"))
+	assert.NoError(t, mock.LoadDump(strings.NewReader(testDump), "store"))
+
+	var stores []*testStore
+	n, err := sess.Select("`main_table`.`store_id`", "main_table.code", "name AS `name`").
+		From("store", "main_table").Where("x = ?", 1).OrderBy("name").LoadStructs(&stores)
+	assert.NoError(t, err)
+	assert.Exactly(t, 2, n)
+	assert.Exactly(t, "admin", stores[0].Code.String)
+	assert.Exactly(t, int64(1), stores[1].StoreID)
+	assert.False(t, stores[1].Code.Valid)
+	assert.Exactly(t, "It's, (DE)", stores[1].Name)
+
+	c, err := sess.Select("COUNT(*)").From("store").ReturnInt64()
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(2), c)
+
+	_, err = sess.Select("unknown").From("store").ReturnInt64()
+	assert.Error(t, err)
+	_, err = sess.Select("id").From("other").ReturnInt64()
+	assert.Error(t, err)
+
+	mock.AddFixture("other", csdbtest.NewRows("id").AddRow(7))
+	id, err := sess.Select("*").From("other").ReturnInt64()
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(7), id)
+}
+
+func TestMockLoadDumpFile(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+
+	assert.NoError(t, mock.LoadDumpFile("../../../testData/magento-2.0-0.74.0-beta12.sql", "store", "store_website"))
+
+	var stores []*testStore
+	n, err := dbr.NewConnection(db, nil).NewSession(nil).Select("store_id", "code", "website_id", "name").From("store").LoadStructs(&stores)
+	assert.NoError(t, err)
+	assert.True(t, n > 1)
+	assert.Exactly(t, "admin", stores[0].Code.String)
+}
@@ -0,0 +1,43 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package csdbtest provides an in-process fake database/sql driver to test code
+which uses csdb and dbr without a running MySQL server.
+
+The driver records all executed statements with their arguments and answers
+queries from registered expectations or from fixture tables. Fixtures can be
+loaded from MySQL dumps like the files in the testData directory.
+
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	mock.ExpectQuery("FROM `core_config_data`").
+		WillReturnRows(csdbtest.NewRows("config_id", "scope", "scope_id", "path", "value").
+			AddRow(1, "default", 0, "web/seo/use_rewrites", "1"))
+
+	sess := dbr.NewConnection(db, nil).NewSession(nil)
+	// run the code under test with sess
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+Without an expectation a SELECT will be answered from the fixture table named
+after FROM. Only the selected columns are returned, WHERE, JOIN, ORDER BY and
+LIMIT are ignored.
+
+	if err := mock.LoadDumpFile("../testData/magento-2.0-0.74.0-beta12.sql", "store", "store_group"); err != nil {
+		t.Fatal(err)
+	}
+*/
+package csdbtest
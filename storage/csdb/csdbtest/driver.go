@@ -0,0 +1,176 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// DriverName is the name of the registered database/sql driver.
+const DriverName = "csdbtest"
+
+var (
+	mocksMu sync.Mutex
+	mocks   = make(map[string]*Mock)
+)
+
+func init() {
+	sql.Register(DriverName, fakeDriver{})
+}
+
+// register adds the mock to the registry and returns its DSN.
+func register(m *Mock) string {
+	mocksMu.Lock()
+	defer mocksMu.Unlock()
+	dsn := "mock_" + strconv.Itoa(len(mocks))
+	mocks[dsn] = m
+	return dsn
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	mocksMu.Lock()
+	m, ok := mocks[dsn]
+	mocksMu.Unlock()
+	if !ok {
+		return nil, errors.New("csdbtest: unknown DSN " + dsn + ", use NewDB()")
+	}
+	return &conn{m: m}, nil
+}
+
+type conn struct {
+	m *Mock
+}
+
+var (
+	_ driver.Conn           = (*conn)(nil)
+	_ driver.ExecerContext  = (*conn)(nil)
+	_ driver.QueryerContext = (*conn)(nil)
+	_ driver.Pinger         = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{c: c, query: query}, nil }
+func (c *conn) Close() error                              { return nil }
+func (c *conn) Ping(ctx context.Context) error            { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	c.m.record("BEGIN", nil)
+	return c, nil
+}
+
+func (c *conn) Commit() error {
+	c.m.record("COMMIT", nil)
+	return nil
+}
+
+func (c *conn) Rollback() error {
+	c.m.record("ROLLBACK", nil)
+	return nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.exec(query, namedValues(args))
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.query(query, namedValues(args))
+}
+
+func (c *conn) exec(query string, args []driver.Value) (driver.Result, error) {
+	e, err := c.m.match(false, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return result{lastInsertID: e.lastInsertID, rowsAffected: e.rowsAffected}, nil
+}
+
+func (c *conn) query(query string, args []driver.Value) (driver.Rows, error) {
+	e, err := c.m.match(true, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		r, err := c.m.fixtureRows(query)
+		if err != nil {
+			return nil, err
+		}
+		return &rows{r: r}, nil
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.rows == nil {
+		return &rows{r: &Rows{}}, nil
+	}
+	return &rows{r: e.rows}, nil
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	if len(args) == 0 {
+		return nil
+	}
+	vals := make([]driver.Value, len(args))
+	for i, a := range args {
+		vals[i] = a.Value
+	}
+	return vals
+}
+
+type stmt struct {
+	c     *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.exec(s.query, args)
+}
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.query(s.query, args)
+}
+
+type result struct {
+	lastInsertID, rowsAffected int64
+}
+
+func (r result) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r result) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+type rows struct {
+	r   *Rows
+	pos int
+}
+
+func (r *rows) Columns() []string { return r.r.columns }
+func (r *rows) Close() error      { return nil }
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.r.values) {
+		return io.EOF
+	}
+	copy(dest, r.r.values[r.pos])
+	r.pos++
+	return nil
+}
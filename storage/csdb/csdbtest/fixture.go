@@ -0,0 +1,293 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// AddFixture sets the rows of a table. Queries without a matching expectation
+// will be answered from the fixture tables.
+func (m *Mock) AddFixture(table string, r *Rows) *Mock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tables[table] = r
+	return m
+}
+
+// LoadDumpFile loads the tables of a MySQL dump file as fixtures, see LoadDump.
+func (m *Mock) LoadDumpFile(file string, tables ...string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.LoadDump(f, tables...)
+}
+
+// LoadDump loads the tables of a MySQL dump as fixtures. The column names
+// will be taken from the CREATE TABLE statements and the rows from the
+// INSERT INTO ... VALUES statements. All values are returned as []byte or nil
+// like the MySQL text protocol does. If tables are provided only these tables
+// will be loaded.
+func (m *Mock) LoadDump(r io.Reader, tables ...string) error {
+	want := func(t string) bool {
+		if len(tables) == 0 {
+			return true
+		}
+		for _, w := range tables {
+			if w == t {
+				return true
+			}
+		}
+		return false
+	}
+
+	loaded := make(map[string]*Rows)
+	var current *Rows // table of the current CREATE TABLE statement
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "CREATE TABLE "):
+			current = nil
+			if t := tableName(line[len("CREATE TABLE "):]); want(t) {
+				current = &Rows{}
+				loaded[t] = current
+			}
+		case current != nil && strings.HasPrefix(line, "  `"):
+			current.columns = append(current.columns, tableName(line[2:]))
+		case strings.HasPrefix(line, ")"):
+			current = nil
+		case strings.HasPrefix(line, "INSERT INTO "):
+			t := tableName(line[len("INSERT INTO "):])
+			if !want(t) {
+				break
+			}
+			tr, ok := loaded[t]
+			if !ok {
+				return fmt.Errorf("csdbtest: INSERT INTO %s without CREATE TABLE", t)
+			}
+			i := strings.Index(line, " VALUES ")
+			if i < 0 {
+				return fmt.Errorf("csdbtest: cannot parse INSERT INTO %s", t)
+			}
+			if err := parseValues(line[i+len(" VALUES "):], tr); err != nil {
+				return fmt.Errorf("csdbtest: table %s: %s", t, err)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	for _, t := range tables {
+		if _, ok := loaded[t]; !ok {
+			return fmt.Errorf("csdbtest: table %s not found in dump", t)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for t, tr := range loaded {
+		m.tables[t] = tr
+	}
+	return nil
+}
+
+// tableName returns the first backtick quoted or plain word.
+func tableName(s string) string {
+	if strings.HasPrefix(s, "`") {
+		if i := strings.IndexByte(s[1:], '`'); i >= 0 {
+			return s[1 : i+1]
+		}
+	}
+	if i := strings.IndexAny(s, " (,"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// parseValues parses (1,'a',NULL),(2,'b\'c',NULL); into the rows.
+func parseValues(s string, r *Rows) error {
+	i := 0
+	for i < len(s) {
+		if s[i] != '(' {
+			return fmt.Errorf("expected ( at position %d", i)
+		}
+		i++
+		var row []driver.Value
+		for {
+			if i >= len(s) {
+				return errors.New("unexpected end of row")
+			}
+			if s[i] == '\'' {
+				var b []byte
+				for i++; i < len(s) && s[i] != '\''; i++ {
+					if s[i] == '\\' && i+1 < len(s) {
+						i++
+						b = append(b, unescape(s[i]))
+						continue
+					}
+					b = append(b, s[i])
+				}
+				i++ // closing quote
+				row = append(row, b)
+			} else {
+				j := i
+				for j < len(s) && s[j] != ',' && s[j] != ')' {
+					j++
+				}
+				if v := s[i:j]; v == "NULL" {
+					row = append(row, nil)
+				} else {
+					row = append(row, []byte(v))
+				}
+				i = j
+			}
+			if i >= len(s) {
+				return errors.New("unexpected end of row")
+			}
+			if s[i] == ')' {
+				i++
+				break
+			}
+			i++ // comma
+		}
+		if len(row) != len(r.columns) {
+			return fmt.Errorf("%d values for %d columns", len(row), len(r.columns))
+		}
+		r.values = append(r.values, row)
+		if i < len(s) && (s[i] == ',' || s[i] == ';') {
+			i++
+		}
+	}
+	return nil
+}
+
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case '0':
+		return 0
+	case 'Z':
+		return 26
+	}
+	return c
+}
+
+// fixtureRows answers a SELECT from the fixture table after FROM. Only the
+// selected columns will be returned. SELECT COUNT(*) returns the number of
+// rows.
+func (m *Mock) fixtureRows(query string) (*Rows, error) {
+	q := normalize(query)
+	upper := strings.ToUpper(q)
+	from := strings.Index(upper, " FROM ")
+	if !strings.HasPrefix(upper, "SELECT ") || from < 0 {
+		return nil, fmt.Errorf("csdbtest: unexpected query %q", query)
+	}
+	table := tableName(q[from+len(" FROM "):])
+
+	m.mu.Lock()
+	tr, ok := m.tables[table]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("csdbtest: unexpected query %q and no fixture for table %q", query, table)
+	}
+
+	cols := splitColumns(strings.TrimPrefix(q[len("SELECT "):from], "DISTINCT "))
+	if len(cols) == 1 && strings.ToUpper(cols[0]) == "COUNT(*)" {
+		return NewRows(cols[0]).AddRow(tr.Len()), nil
+	}
+
+	res := &Rows{}
+	var idx []int
+	for _, c := range cols {
+		name, src := c, c
+		if i := strings.Index(strings.ToUpper(c), " AS "); i >= 0 {
+			name, src = c[i+len(" AS "):], c[:i]
+		}
+		if i := strings.LastIndexByte(src, '.'); i >= 0 {
+			src = src[i+1:]
+		}
+		src, name = strings.Trim(src, "` "), strings.Trim(name, "` ")
+		if i := strings.LastIndexByte(name, '.'); i >= 0 {
+			name = strings.Trim(name[i+1:], "`")
+		}
+		if src == "*" {
+			for i, tc := range tr.columns {
+				res.columns = append(res.columns, tc)
+				idx = append(idx, i)
+			}
+			continue
+		}
+		pos := -1
+		for i, tc := range tr.columns {
+			if tc == src {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			return nil, fmt.Errorf("csdbtest: column %q not found in fixture table %q", c, table)
+		}
+		res.columns = append(res.columns, name)
+		idx = append(idx, pos)
+	}
+
+	for _, v := range tr.values {
+		row := make([]driver.Value, len(idx))
+		for i, p := range idx {
+			row[i] = v[p]
+		}
+		res.values = append(res.values, row)
+	}
+	return res, nil
+}
+
+// splitColumns splits the column list at the commas outside of parentheses.
+func splitColumns(s string) []string {
+	var cols []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				cols = append(cols, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(cols, strings.TrimSpace(s[start:]))
+}
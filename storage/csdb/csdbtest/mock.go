@@ -0,0 +1,255 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csdbtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Statement is an executed statement with its arguments. Transactions are
+// recorded as BEGIN, COMMIT and ROLLBACK.
+type Statement struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// Mock records the statements of a fake database and answers them from the
+// expectations or the fixture tables. A Mock is safe for concurrent use.
+type Mock struct {
+	mu           sync.Mutex
+	ordered      bool
+	expectations []*Expectation
+	statements   []Statement
+	tables       map[string]*Rows
+}
+
+// NewDB creates a new database connected to a new Mock. Expectations must be
+// matched in order, see MatchExpectationsInOrder.
+func NewDB() (*sql.DB, *Mock) {
+	m := &Mock{ordered: true, tables: make(map[string]*Rows)}
+	db, err := sql.Open(DriverName, register(m))
+	if err != nil {
+		panic(err) // can only fail if the driver is not registered
+	}
+	return db, m
+}
+
+// MatchExpectationsInOrder sets whether the statements must arrive in the
+// order of the expectations. Default true. Queries answered by the fixtures
+// can always be interleaved.
+func (m *Mock) MatchExpectationsInOrder(ordered bool) *Mock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ordered = ordered
+	return m
+}
+
+// ExpectQuery adds an expectation for a query which contains sql. White space
+// will be normalized before comparing.
+func (m *Mock) ExpectQuery(sql string) *Expectation {
+	return m.expect(true, sql)
+}
+
+// ExpectExec adds an expectation for an INSERT, UPDATE, DELETE or any other
+// statement which does not return rows. The statement must contain sql.
+func (m *Mock) ExpectExec(sql string) *Expectation {
+	return m.expect(false, sql)
+}
+
+func (m *Mock) expect(query bool, sql string) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &Expectation{query: query, sql: normalize(sql), times: 1}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// Statements returns all executed statements in the order of their execution.
+func (m *Mock) Statements() []Statement {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Statement(nil), m.statements...)
+}
+
+// ExpectationsWereMet returns an error if an expectation has not been
+// triggered often enough.
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.expectations {
+		if e.times > 0 {
+			return fmt.Errorf("csdbtest: expectation %s has not been met", e)
+		}
+	}
+	return nil
+}
+
+// record appends a statement to the log.
+func (m *Mock) record(sql string, args []driver.Value) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statements = append(m.statements, Statement{SQL: sql, Args: args})
+}
+
+// match records the statement and returns the matching expectation. Returns
+// nil without an error if a query should be answered from the fixtures.
+func (m *Mock) match(query bool, sql string, args []driver.Value) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statements = append(m.statements, Statement{SQL: sql, Args: args})
+
+	n := normalize(sql)
+	var next *Expectation // first expectation which has not been met
+	for _, e := range m.expectations {
+		if e.times == 0 {
+			continue
+		}
+		if next == nil && e.times > 0 {
+			next = e
+		}
+		if !e.matches(query, n, args) {
+			continue
+		}
+		if m.ordered && next != nil && next != e {
+			return nil, fmt.Errorf("csdbtest: statement %q arrived before the expectation %s", sql, next)
+		}
+		if e.times > 0 {
+			e.times--
+		}
+		return e, nil
+	}
+	if query {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("csdbtest: unexpected statement %q with args %v", sql, args)
+}
+
+// Expectation is an expected statement and its result, created by
+// Mock.ExpectQuery or Mock.ExpectExec.
+type Expectation struct {
+	query   bool
+	sql     string
+	args    []driver.Value
+	hasArgs bool
+	// times remaining number of calls, -1 for any number
+	times        int
+	rows         *Rows
+	lastInsertID int64
+	rowsAffected int64
+	err          error
+}
+
+func (e *Expectation) String() string {
+	if e.hasArgs {
+		return fmt.Sprintf("%q with args %v", e.sql, e.args)
+	}
+	return fmt.Sprintf("%q", e.sql)
+}
+
+// WithArgs requires the arguments of the statement. dbr interpolates the
+// arguments into the SQL unless the statement cache is enabled.
+func (e *Expectation) WithArgs(args ...interface{}) *Expectation {
+	e.args = convertValues(args)
+	e.hasArgs = true
+	return e
+}
+
+// WillReturnRows sets the result of a query. Without rows a query returns
+// an empty result set.
+func (e *Expectation) WillReturnRows(r *Rows) *Expectation {
+	e.rows = r
+	return e
+}
+
+// WillReturnResult sets the result of an Exec.
+func (e *Expectation) WillReturnResult(lastInsertID, rowsAffected int64) *Expectation {
+	e.lastInsertID, e.rowsAffected = lastInsertID, rowsAffected
+	return e
+}
+
+// WillReturnError lets the statement fail with err.
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Times sets how often the statement is expected, default once.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// AnyTimes allows the statement to be executed any number of times, also
+// never.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = -1
+	return e
+}
+
+func (e *Expectation) matches(query bool, sql string, args []driver.Value) bool {
+	if e.query != query || !strings.Contains(sql, e.sql) {
+		return false
+	}
+	if !e.hasArgs || len(e.args) == 0 && len(args) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(e.args, args)
+}
+
+// Rows is the result set of a query or the rows of a fixture table.
+type Rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+// NewRows creates an empty result set with the column names.
+func NewRows(columns ...string) *Rows {
+	return &Rows{columns: columns}
+}
+
+// AddRow appends a row. The values will be converted like arguments of a
+// query, e.g. an int becomes an int64. Panics if the number of values does
+// not match the columns.
+func (r *Rows) AddRow(values ...interface{}) *Rows {
+	if len(values) != len(r.columns) {
+		panic(fmt.Sprintf("csdbtest: %d values for %d columns", len(values), len(r.columns)))
+	}
+	r.values = append(r.values, convertValues(values))
+	return r
+}
+
+// Len returns the number of rows.
+func (r *Rows) Len() int { return len(r.values) }
+
+func convertValues(vals []interface{}) []driver.Value {
+	dv := make([]driver.Value, len(vals))
+	for i, v := range vals {
+		var err error
+		if dv[i], err = driver.DefaultParameterConverter.ConvertValue(v); err != nil {
+			panic(err)
+		}
+	}
+	return dv
+}
+
+// normalize replaces all white space sequences with one space.
+func normalize(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
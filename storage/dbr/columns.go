@@ -29,7 +29,18 @@ func TableColumnQuote(t string, cols ...string) []string {
 	return cols
 }
 
-// IfNullAs returns IFNULL(t1.c1,t2.c2) AS as
+// IfNullAs returns IFNULL(t1.c1,t2.c2) AS as for MySQL. Use Session.IfNullAs
+// for the dialect of the connection.
 func IfNullAs(t1, c1, t2, c2, as string) string {
-	return "IFNULL(" + Quote + t1 + Quote + "." + Quote + c1 + Quote + ", " + Quote + t2 + Quote + "." + Quote + c2 + Quote + ") AS " + Quote + as + Quote
+	return ifNullAs(MySQL, t1, c1, t2, c2, as)
+}
+
+// IfNullAs returns IFNULL(t1.c1,t2.c2) AS as in the dialect of the
+// connection, e.g. COALESCE for PostgreSQL.
+func (sess *Session) IfNullAs(t1, c1, t2, c2, as string) string {
+	return ifNullAs(sess.dialect(), t1, c1, t2, c2, as)
+}
+
+func ifNullAs(d Dialect, t1, c1, t2, c2, as string) string {
+	return d.IfNull(Quote+t1+Quote+"."+Quote+c1+Quote, Quote+t2+Quote+"."+Quote+c2+Quote) + " AS " + Quote + as + Quote
 }
@@ -0,0 +1,300 @@
+package dbr
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Condition is a node of a condition tree. A Condition can be passed to
+// Where() and Having() of the SELECT, UPDATE and DELETE builders and to
+// JoinOn(). Conditions are created with the functions And, Or, Not, Equal,
+// NotEqual, Greater, GreaterOrEqual, Less, LessOrEqual, In, NotIn, Between,
+// NotBetween, Like, NotLike, IsNull, IsNotNull, Exists, NotExists and Expr.
+//
+// Column names get quoted by the Quoter. A dot separates the table alias,
+// e.g. "e.entity_id" renders as `e`.`entity_id`. Column names containing
+// parentheses, spaces or quotes will be written unquoted.
+type Condition interface {
+	// ToSql returns the condition with placeholders and its arguments.
+	ToSql() (string, []interface{})
+	writeTo(sql *bytes.Buffer, args *[]interface{})
+}
+
+type (
+	condList struct {
+		op    string
+		conds []Condition
+	}
+	condNot struct {
+		c Condition
+	}
+	condCmp struct {
+		column string
+		op     string
+		value  interface{}
+	}
+	condIn struct {
+		column string
+		not    bool
+		values interface{}
+	}
+	condBetween struct {
+		column   string
+		not      bool
+		from, to interface{}
+	}
+	condNull struct {
+		column string
+		not    bool
+	}
+	condExists struct {
+		not bool
+		sb  *SelectBuilder
+	}
+)
+
+// And joins the conditions with AND. Without conditions it renders 1=1.
+func And(conds ...Condition) Condition { return condList{op: " AND ", conds: conds} }
+
+// Or joins the conditions with OR. Without conditions it renders 1=0.
+func Or(conds ...Condition) Condition { return condList{op: " OR ", conds: conds} }
+
+// Not negates a condition.
+func Not(c Condition) Condition { return condNot{c: c} }
+
+// Equal creates `column` = value. A nil value renders IS NULL. The value can
+// be an Expr() or a *SelectBuilder for a scalar subquery.
+func Equal(column string, value interface{}) Condition {
+	if value == nil {
+		return IsNull(column)
+	}
+	return condCmp{column: column, op: " = ", value: value}
+}
+
+// NotEqual creates `column` != value. A nil value renders IS NOT NULL.
+func NotEqual(column string, value interface{}) Condition {
+	if value == nil {
+		return IsNotNull(column)
+	}
+	return condCmp{column: column, op: " != ", value: value}
+}
+
+// Greater creates `column` > value
+func Greater(column string, value interface{}) Condition {
+	return condCmp{column: column, op: " > ", value: value}
+}
+
+// GreaterOrEqual creates `column` >= value
+func GreaterOrEqual(column string, value interface{}) Condition {
+	return condCmp{column: column, op: " >= ", value: value}
+}
+
+// Less creates `column` < value
+func Less(column string, value interface{}) Condition {
+	return condCmp{column: column, op: " < ", value: value}
+}
+
+// LessOrEqual creates `column` <= value
+func LessOrEqual(column string, value interface{}) Condition {
+	return condCmp{column: column, op: " <= ", value: value}
+}
+
+// Like creates `column` LIKE pattern
+func Like(column string, pattern interface{}) Condition {
+	return condCmp{column: column, op: " LIKE ", value: pattern}
+}
+
+// NotLike creates `column` NOT LIKE pattern
+func NotLike(column string, pattern interface{}) Condition {
+	return condCmp{column: column, op: " NOT LIKE ", value: pattern}
+}
+
+// In creates `column` IN (?,?,...) with one placeholder per element of the
+// slice values. An empty slice renders 1=0 which matches nothing. The values
+// can be a *SelectBuilder or *UnionBuilder for IN (SELECT ...). A value which
+// is not a slice will be treated as a slice with one element.
+func In(column string, values interface{}) Condition {
+	return condIn{column: column, values: values}
+}
+
+// NotIn creates `column` NOT IN (?,?,...). An empty slice renders 1=1 which
+// matches everything. See In().
+func NotIn(column string, values interface{}) Condition {
+	return condIn{column: column, not: true, values: values}
+}
+
+// Between creates `column` BETWEEN from AND to
+func Between(column string, from, to interface{}) Condition {
+	return condBetween{column: column, from: from, to: to}
+}
+
+// NotBetween creates `column` NOT BETWEEN from AND to
+func NotBetween(column string, from, to interface{}) Condition {
+	return condBetween{column: column, not: true, from: from, to: to}
+}
+
+// IsNull creates `column` IS NULL
+func IsNull(column string) Condition { return condNull{column: column} }
+
+// IsNotNull creates `column` IS NOT NULL
+func IsNotNull(column string) Condition { return condNull{column: column, not: true} }
+
+// Exists creates EXISTS (SELECT ...)
+func Exists(sb *SelectBuilder) Condition { return condExists{sb: sb} }
+
+// NotExists creates NOT EXISTS (SELECT ...)
+func NotExists(sb *SelectBuilder) Condition { return condExists{not: true, sb: sb} }
+
+func (c condList) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	if len(c.conds) == 0 {
+		if c.op == " OR " {
+			sql.WriteString("1=0")
+		} else {
+			sql.WriteString("1=1")
+		}
+		return
+	}
+	for i, cond := range c.conds {
+		if i > 0 {
+			sql.WriteString(c.op)
+		}
+		sql.WriteRune('(')
+		cond.writeTo(sql, args)
+		sql.WriteRune(')')
+	}
+}
+
+func (c condNot) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	sql.WriteString("NOT (")
+	c.c.writeTo(sql, args)
+	sql.WriteRune(')')
+}
+
+func (c condCmp) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	Quoter.writeQuotedIdentifier(c.column, sql)
+	sql.WriteString(c.op)
+	writeValue(c.value, sql, args)
+}
+
+func (c condIn) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	switch c.values.(type) {
+	case *SelectBuilder, *UnionBuilder:
+		Quoter.writeQuotedIdentifier(c.column, sql)
+		if c.not {
+			sql.WriteString(" NOT")
+		}
+		sql.WriteString(" IN ")
+		writeValue(c.values, sql, args)
+		return
+	}
+
+	v := reflect.ValueOf(c.values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array || v.Type().Elem().Kind() == reflect.Uint8 {
+		v = reflect.ValueOf([]interface{}{c.values})
+	}
+	if v.Len() == 0 {
+		if c.not {
+			sql.WriteString("1=1")
+		} else {
+			sql.WriteString("1=0")
+		}
+		return
+	}
+
+	Quoter.writeQuotedIdentifier(c.column, sql)
+	if c.not {
+		sql.WriteString(" NOT")
+	}
+	sql.WriteString(" IN (")
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			sql.WriteRune(',')
+		}
+		sql.WriteRune('?')
+		*args = append(*args, v.Index(i).Interface())
+	}
+	sql.WriteRune(')')
+}
+
+func (c condBetween) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	Quoter.writeQuotedIdentifier(c.column, sql)
+	if c.not {
+		sql.WriteString(" NOT")
+	}
+	sql.WriteString(" BETWEEN ")
+	writeValue(c.from, sql, args)
+	sql.WriteString(" AND ")
+	writeValue(c.to, sql, args)
+}
+
+func (c condNull) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	Quoter.writeQuotedIdentifier(c.column, sql)
+	if c.not {
+		sql.WriteString(" IS NOT NULL")
+	} else {
+		sql.WriteString(" IS NULL")
+	}
+}
+
+func (c condExists) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	if c.not {
+		sql.WriteString("NOT ")
+	}
+	sql.WriteString("EXISTS ")
+	writeValue(c.sb, sql, args)
+}
+
+// writeTo makes an Expr usable as a Condition.
+func (e *expr) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	sql.WriteString(e.Sql)
+	*args = append(*args, e.Values...)
+}
+
+// writeValue writes a placeholder and appends the value to the arguments.
+// Expressions and subqueries will be written into the SQL.
+func writeValue(v interface{}, sql *bytes.Buffer, args *[]interface{}) {
+	switch vt := v.(type) {
+	case *expr:
+		vt.writeTo(sql, args)
+	case *SelectBuilder, *UnionBuilder:
+		q, a := vt.(querier).ToSql()
+		sql.WriteRune('(')
+		sql.WriteString(q)
+		sql.WriteRune(')')
+		*args = append(*args, a...)
+	default:
+		sql.WriteRune('?')
+		*args = append(*args, v)
+	}
+}
+
+func condToSql(c Condition) (string, []interface{}) {
+	var sql bytes.Buffer
+	var args []interface{}
+	c.writeTo(&sql, &args)
+	return sql.String(), args
+}
+
+// ToSql returns the condition with placeholders and its arguments.
+func (c condList) ToSql() (string, []interface{}) { return condToSql(c) }
+
+// ToSql returns the condition with placeholders and its arguments.
+func (c condNot) ToSql() (string, []interface{}) { return condToSql(c) }
+
+// ToSql returns the condition with placeholders and its arguments.
+func (c condCmp) ToSql() (string, []interface{}) { return condToSql(c) }
+
+// ToSql returns the condition with placeholders and its arguments.
+func (c condIn) ToSql() (string, []interface{}) { return condToSql(c) }
+
+// ToSql returns the condition with placeholders and its arguments.
+func (c condBetween) ToSql() (string, []interface{}) { return condToSql(c) }
+
+// ToSql returns the condition with placeholders and its arguments.
+func (c condNull) ToSql() (string, []interface{}) { return condToSql(c) }
+
+// ToSql returns the condition with placeholders and its arguments.
+func (c condExists) ToSql() (string, []interface{}) { return condToSql(c) }
+
+// ToSql returns the expression with placeholders and its arguments.
+func (e *expr) ToSql() (string, []interface{}) { return e.Sql, e.Values }
@@ -0,0 +1,93 @@
+package dbr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionToSql(t *testing.T) {
+	s := createFakeSession()
+	tests := []struct {
+		c        Condition
+		wantSql  string
+		wantArgs []interface{}
+	}{
+		{Equal("a", 1), "`a` = ?", []interface{}{1}},
+		{Equal("e.entity_id", 1), "`e`.`entity_id` = ?", []interface{}{1}},
+		{Equal("a", nil), "`a` IS NULL", nil},
+		{NotEqual("a", nil), "`a` IS NOT NULL", nil},
+		{NotEqual("a", "x"), "`a` != ?", []interface{}{"x"}},
+		{Greater("a", 1), "`a` > ?", []interface{}{1}},
+		{GreaterOrEqual("a", 1), "`a` >= ?", []interface{}{1}},
+		{Less("a", 1), "`a` < ?", []interface{}{1}},
+		{LessOrEqual("COUNT(*)", 1), "COUNT(*) <= ?", []interface{}{1}},
+		{Like("sku", "abc%"), "`sku` LIKE ?", []interface{}{"abc%"}},
+		{NotLike("sku", "abc%"), "`sku` NOT LIKE ?", []interface{}{"abc%"}},
+		{In("a", []int{1, 2, 3}), "`a` IN (?,?,?)", []interface{}{1, 2, 3}},
+		{In("a", 4), "`a` IN (?)", []interface{}{4}},
+		{In("a", []byte("x")), "`a` IN (?)", []interface{}{[]byte("x")}},
+		{In("a", []int{}), "1=0", nil},
+		{NotIn("a", []string{"x", "y"}), "`a` NOT IN (?,?)", []interface{}{"x", "y"}},
+		{NotIn("a", []string{}), "1=1", nil},
+		{Between("a", 1, 5), "`a` BETWEEN ? AND ?", []interface{}{1, 5}},
+		{NotBetween("a", 1, Expr("NOW()")), "`a` NOT BETWEEN ? AND NOW()", []interface{}{1}},
+		{IsNull("a"), "`a` IS NULL", nil},
+		{IsNotNull("a"), "`a` IS NOT NULL", nil},
+		{And(), "1=1", nil},
+		{Or(), "1=0", nil},
+		{Not(Equal("a", 1)), "NOT (`a` = ?)", []interface{}{1}},
+		{
+			And(Equal("a", 1), Or(Like("b", "x%"), IsNull("b")), Expr("c > ?", 3)),
+			"(`a` = ?) AND ((`b` LIKE ?) OR (`b` IS NULL)) AND (c > ?)",
+			[]interface{}{1, "x%", 3},
+		},
+		{
+			In("entity_id", s.Select("entity_id").From("catalog_product_entity_int").Where(Equal("value", 5))),
+			"`entity_id` IN (SELECT entity_id FROM catalog_product_entity_int WHERE (`value` = ?))",
+			[]interface{}{5},
+		},
+		{
+			NotExists(s.Select("1").From("b").Where("b.id = a.id")),
+			"NOT EXISTS (SELECT 1 FROM b WHERE (b.id = a.id))",
+			nil,
+		},
+		{
+			Greater("price", s.Select("AVG(price)").From("p").Where(Equal("store_id", 1))),
+			"`price` > (SELECT AVG(price) FROM p WHERE (`store_id` = ?))",
+			[]interface{}{1},
+		},
+	}
+	for _, test := range tests {
+		sql, args := test.c.ToSql()
+		assert.Exactly(t, test.wantSql, sql)
+		assert.Exactly(t, test.wantArgs, args, "SQL: %s", sql)
+	}
+}
+
+func TestConditionBuilders(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args := s.Select("a").From("b").
+		Where(Or(Equal("a", 1), In("c", []int64{2, 3}))).
+		Where("d = ?", 4).
+		Having(Greater("COUNT(*)", 5)).
+		GroupBy("a").ToSql()
+	assert.Equal(t, "SELECT a FROM b WHERE ((`a` = ?) OR (`c` IN (?,?))) AND (d = ?) GROUP BY a HAVING (COUNT(*) > ?)", sql)
+	assert.Equal(t, []interface{}{1, int64(2), int64(3), 4, 5}, args)
+
+	sql, args = s.Select("e.entity_id").From("catalog_product_entity", "e").
+		Join(JoinTable("catalog_product_entity_int", "i"), nil,
+			JoinOn(And(Equal("i.entity_id", Expr("`e`.`entity_id`")), Equal("i.attribute_id", 96))),
+		).ToSql()
+	assert.Equal(t, "SELECT e.entity_id FROM `catalog_product_entity` AS `e` INNER JOIN `catalog_product_entity_int` AS `i` ON ((`i`.`entity_id` = `e`.`entity_id`) AND (`i`.`attribute_id` = ?))", sql)
+	assert.Equal(t, []interface{}{96}, args)
+
+	sql, args = s.Update("a").Set("b", 1).Where(Between("c", 1, 2)).ToSql()
+	assert.Equal(t, "UPDATE a SET `b` = ? WHERE (`c` BETWEEN ? AND ?)", sql)
+	assert.Equal(t, []interface{}{1, 1, 2}, args)
+
+	sql, args = s.DeleteFrom("a").Where(In("b", []int{})).ToSql()
+	assert.Equal(t, "DELETE FROM a WHERE (1=0)", sql)
+	assert.Nil(t, args)
+}
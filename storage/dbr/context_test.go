@@ -0,0 +1,112 @@
+package dbr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type contextReceiver struct {
+	NullEventReceiver
+	ctxTimings []string
+	ctxErrs    []string
+}
+
+func (r *contextReceiver) EventErrKvContext(ctx context.Context, eventName string, err error, kvs map[string]string) error {
+	r.ctxErrs = append(r.ctxErrs, eventName+":"+ctx.Value("reqID").(string))
+	return err
+}
+
+func (r *contextReceiver) TimingKvContext(ctx context.Context, eventName string, nanoseconds int64, kvs map[string]string) {
+	r.ctxTimings = append(r.ctxTimings, eventName+":"+ctx.Value("reqID").(string))
+}
+
+func TestSessionWithTimeout(t *testing.T) {
+	s := createFakeSession()
+
+	ctx, cancel := s.withTimeout(nil)
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	cancel()
+
+	s.SetTimeout(time.Second)
+	ctx, cancel = s.withTimeout(context.Background())
+	dl, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.True(t, dl.After(time.Now()))
+	cancel()
+	assert.EqualError(t, ctx.Err(), context.Canceled.Error())
+
+	// an existing deadline has precedence
+	parent, pCancel := context.WithTimeout(context.Background(), time.Hour)
+	defer pCancel()
+	ctx, cancel = s.withTimeout(parent)
+	defer cancel()
+	dl, _ = ctx.Deadline()
+	assert.True(t, dl.After(time.Now().Add(time.Minute)))
+}
+
+func TestContextEventReceiver(t *testing.T) {
+	r := &contextReceiver{}
+	ctx := context.WithValue(context.Background(), "reqID", "r1")
+	err := errors.New("boom")
+
+	assert.Exactly(t, err, eventErrKv(ctx, r, "dbr.test", err, nil))
+	timingKv(ctx, r, "dbr.test", 1, nil)
+	assert.Exactly(t, []string{"dbr.test:r1"}, r.ctxErrs)
+	assert.Exactly(t, []string{"dbr.test:r1"}, r.ctxTimings)
+
+	// falls back to the receiver without context
+	assert.Exactly(t, err, eventErrKv(ctx, nullReceiver, "dbr.test", err, nil))
+	timingKv(ctx, nullReceiver, "dbr.test", 1, nil)
+}
+
+func TestSelectLoadStructsContextReal(t *testing.T) {
+	s := createRealSessionWithFixtures()
+
+	var people []*dbrPerson
+	count, err := s.Select("id", "name", "email").From("dbr_people").OrderBy("id ASC").LoadStructsContext(context.Background(), &people)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	people = nil
+	_, err = s.Select("id", "name", "email").From("dbr_people").LoadStructsContext(ctx, &people)
+	assert.Error(t, err)
+	assert.Nil(t, people)
+}
+
+func TestExecContextReal(t *testing.T) {
+	s := createRealSessionWithFixtures()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.InsertInto("dbr_people").Columns("name", "email").Values("Barack", "obama@whitehouse.gov").ExecContext(ctx)
+	assert.Error(t, err)
+	_, err = s.Update("dbr_people").Set("name", "Barack").ExecContext(ctx)
+	assert.Error(t, err)
+	_, err = s.DeleteFrom("dbr_people").ExecContext(ctx)
+	assert.Error(t, err)
+
+	n, err := s.Select("COUNT(*)").From("dbr_people").ReturnInt64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestBeginTxReal(t *testing.T) {
+	s := createRealSessionWithFixtures()
+
+	tx, err := s.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	assert.NoError(t, err)
+
+	var name string
+	err = tx.Select("name").From("dbr_people").Where("email = ?", "jonathan@uservoice.com").LoadValueContext(context.Background(), &name)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jonathan", name)
+	assert.NoError(t, tx.Commit())
+}
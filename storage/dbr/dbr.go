@@ -1,7 +1,9 @@
 package dbr
 
 import (
+	"context"
 	"database/sql"
+	"time"
 )
 
 // Connection is a connection to the database with an EventReceiver
@@ -9,12 +11,25 @@ import (
 type Connection struct {
 	Db *sql.DB
 	EventReceiver
+	// Dialect translates the SQL of the builders, defaults to MySQL.
+	Dialect Dialect
+	// stmts cache for prepared statements, nil if disabled.
+	stmts *StmtCache
+	// replicas read only databases for SELECT statements, see AddReplicas.
+	replicas []*replica
+	next     uint32 // round-robin counter for replicas
 }
 
 // Session represents a business unit of execution for some connection
 type Session struct {
 	cxn *Connection
 	EventReceiver
+	// Timeout applies to all queries of this session whose context has no
+	// deadline. Zero disables the default timeout. Transactions are not
+	// affected, only the statements within.
+	Timeout time.Duration
+	// primary forces SELECT statements to the primary database.
+	primary bool
 }
 
 // NewConnection instantiates a Connection for a given database/sql connection
@@ -24,7 +39,13 @@ func NewConnection(db *sql.DB, log EventReceiver) *Connection {
 		log = nullReceiver
 	}
 
-	return &Connection{Db: db, EventReceiver: log}
+	return &Connection{Db: db, EventReceiver: log, Dialect: MySQL}
+}
+
+// SetDialect sets the SQL dialect of the database, see Dialect.
+func (cxn *Connection) SetDialect(d Dialect) *Connection {
+	cxn.Dialect = d
+	return cxn
 }
 
 // NewSession instantiates a Session for the Connection
@@ -35,12 +56,35 @@ func (cxn *Connection) NewSession(log EventReceiver) *Session {
 	return &Session{cxn: cxn, EventReceiver: log}
 }
 
+// SetTimeout sets the default timeout for the queries of the session.
+// See field Timeout.
+func (sess *Session) SetTimeout(d time.Duration) *Session {
+	sess.Timeout = d
+	return sess
+}
+
+// withTimeout applies the default timeout to a context without deadline. A
+// nil context will be replaced by context.Background(). The returned cancel
+// func must always be called.
+func (sess *Session) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); ok || sess.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, sess.Timeout)
+}
+
 // SessionRunner can do anything that a Session can except start a transaction.
 type SessionRunner interface {
 	Select(cols ...string) *SelectBuilder
 	SelectBySql(sql string, args ...interface{}) *SelectBuilder
+	Union(selects ...*SelectBuilder) *UnionBuilder
+	UnionAll(selects ...*SelectBuilder) *UnionBuilder
 
 	InsertInto(into string) *InsertBuilder
+	ReplaceInto(into string) *InsertBuilder
 	Update(table string) *UpdateBuilder
 	UpdateBySql(sql string, args ...interface{}) *UpdateBuilder
 	DeleteFrom(from string) *DeleteBuilder
@@ -49,4 +93,6 @@ type SessionRunner interface {
 type runner interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	Query(query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 }
@@ -0,0 +1,29 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package dbrtest provides test helpers for the query builders of package dbr.
+
+AssertNoFullScan runs EXPLAIN for a SelectBuilder against a real MySQL
+database and fails the test if one of the named tables or aliases will be read
+completely, e.g. because of a missing index on a join column of the EAV
+tables.
+
+	sel, err := eav.GetAttributeSelectSql(sess, et.AdditionalAttributeTable, et.EntityTypeID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbrtest.AssertNoFullScan(t, sel, csdb.AdditionalTable, csdb.ScopeTable)
+*/
+package dbrtest
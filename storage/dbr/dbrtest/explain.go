@@ -0,0 +1,38 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbrtest
+
+import (
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/dbr"
+)
+
+// AssertNoFullScan fails the test if the query plan of the statement reads
+// one of the tables completely. Tables are matched by their name or alias as
+// listed in the plan. Without tables every full scan fails the test. Returns
+// the plan for further checks or nil if EXPLAIN failed.
+func AssertNoFullScan(t testing.TB, b *dbr.SelectBuilder, tables ...string) *dbr.ExplainPlan {
+	p, err := b.Explain()
+	if err != nil {
+		t.Errorf("dbrtest: EXPLAIN failed: %s", err)
+		return nil
+	}
+	for _, ts := range p.FullScans(tables...) {
+		t.Errorf("dbrtest: full table scan on %q examining %d rows, possible keys %v, condition %q\n%s",
+			ts.Table, ts.RowsExamined, ts.PossibleKeys, ts.Condition, p.JSON)
+	}
+	return p
+}
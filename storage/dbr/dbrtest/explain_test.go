@@ -0,0 +1,61 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbrtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/storage/dbr/dbrtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder collects the errors instead of failing the test.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+const plan = `{"query_block": {"nested_loop": [
+	{"table": {"table_name": "main_table", "access_type": "ALL", "rows_examined_per_scan": 96}},
+	{"table": {"table_name": "additional_table", "access_type": "eq_ref", "key": "PRIMARY", "rows_examined_per_scan": 1}}
+]}}`
+
+func TestAssertNoFullScan(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	mock.ExpectQuery("EXPLAIN FORMAT=JSON SELECT").AnyTimes().
+		WillReturnRows(csdbtest.NewRows("EXPLAIN").AddRow(plan))
+	sel := dbr.NewConnection(db, nil).NewSession(nil).
+		Select("*").From("eav_attribute", "main_table").
+		Join(dbr.JoinTable("catalog_eav_attribute", "additional_table"), nil, dbr.JoinOn("additional_table.attribute_id = main_table.attribute_id"))
+
+	r := &recorder{TB: t}
+	p := dbrtest.AssertNoFullScan(r, sel, "additional_table")
+	assert.NotNil(t, p)
+	assert.Empty(t, r.errs)
+
+	p = dbrtest.AssertNoFullScan(r, sel)
+	assert.NotNil(t, p)
+	if assert.Len(t, r.errs, 1) {
+		assert.Contains(t, r.errs[0], `full table scan on "main_table" examining 96 rows`)
+	}
+}
@@ -2,8 +2,8 @@ package dbr
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
-	"fmt"
 	"time"
 )
 
@@ -41,7 +41,7 @@ func (tx *Tx) DeleteFrom(from string) *DeleteBuilder {
 }
 
 // Where appends a WHERE clause to the statement whereSqlOrMap can be a
-// string or map. If it's a string, args wil replaces any places holders
+// string, map or Condition. If it's a string, args wil replaces any places holders
 func (b *DeleteBuilder) Where(whereSqlOrMap interface{}, args ...interface{}) *DeleteBuilder {
 	b.WhereFragments = append(b.WhereFragments, newWhereFragment(whereSqlOrMap, args))
 	return b
@@ -107,15 +107,7 @@ func (b *DeleteBuilder) ToSql() (string, []interface{}) {
 		}
 	}
 
-	if b.LimitValid {
-		sql.WriteString(" LIMIT ")
-		fmt.Fprint(&sql, b.LimitCount)
-	}
-
-	if b.OffsetValid {
-		sql.WriteString(" OFFSET ")
-		fmt.Fprint(&sql, b.OffsetCount)
-	}
+	sql.WriteString(b.dialect().LimitOffset(b.LimitCount, b.OffsetCount, b.LimitValid, b.OffsetValid))
 
 	return sql.String(), args
 }
@@ -123,20 +115,32 @@ func (b *DeleteBuilder) ToSql() (string, []interface{}) {
 // Exec executes the statement represented by the DeleteBuilder
 // It returns the raw database/sql Result and an error if there was one
 func (b *DeleteBuilder) Exec() (sql.Result, error) {
+	return b.ExecContext(context.Background())
+}
+
+// ExecContext executes the statement represented by the DeleteBuilder. The
+// statement gets cancelled when the context is done. The default timeout of
+// the session applies if the context has no deadline.
+func (b *DeleteBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
 	sql, args := b.ToSql()
 
-	fullSql, err := Interpolate(sql, args)
+	fullSql, args, prepared, err := b.interpolate(sql, args)
 	if err != nil {
-		return nil, b.EventErrKv("dbr.delete.exec.interpolate", err, kvs{"sql": fullSql})
+		return nil, eventErrKv(ctx, b.EventReceiver, "dbr.delete.exec.interpolate", err, kvs{"sql": fullSql})
 	}
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.delete", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		timingKv(ctx, b.EventReceiver, "dbr.delete", time.Since(startTime).Nanoseconds(), timingKvs(fullSql, args))
+	}()
 
-	result, err := b.runner.Exec(fullSql)
+	result, err := b.execContext(ctx, b.runner, fullSql, args, prepared)
 	if err != nil {
-		return result, b.EventErrKv("dbr.delete.exec.exec", err, kvs{"sql": fullSql})
+		return result, eventErrKv(ctx, b.EventReceiver, "dbr.delete.exec.exec", err, kvs{"sql": fullSql})
 	}
 
 	return result, nil
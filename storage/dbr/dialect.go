@@ -0,0 +1,264 @@
+package dbr
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Dialect abstracts the SQL differences between the database systems. The
+// builders create MySQL flavoured SQL with backtick quoted identifiers and ?
+// placeholders. Before execution the SQL gets translated by the Dialect of
+// the Connection. Only MySQL interpolates the arguments into the SQL, the
+// other dialects send the arguments to the driver and expand slices into
+// several placeholders.
+type Dialect interface {
+	// Name returns the name of the database system.
+	Name() string
+	// QuoteIdent quotes a single identifier, e.g. a table or column name.
+	QuoteIdent(name string) string
+	// Placeholder returns the placeholder for the n-th argument starting at 1.
+	Placeholder(n int) string
+	// LimitOffset returns the LIMIT and OFFSET clause with a leading space.
+	LimitOffset(limit, offset uint64, hasLimit, hasOffset bool) string
+	// Insert returns the verb of an INSERT, INSERT IGNORE or REPLACE
+	// statement including a trailing space.
+	Insert(ignore, replace bool) string
+	// OnConflict returns the upsert clause which will be followed by the
+	// assignments if update is true. If update is false the clause for an
+	// INSERT IGNORE gets returned. The conflict columns are the unique key
+	// columns, required by PostgreSQL and SQLite.
+	OnConflict(conflictColumns []string, update bool) string
+	// Excluded returns the expression for the value of a column of the row
+	// which could not be inserted in an upsert assignment.
+	Excluded(column string) string
+	// Now returns the current date and time function.
+	Now() string
+	// IfNull returns an expression which returns alt if expr is NULL.
+	IfNull(expr, alt string) string
+	// UnionOperand wraps a SELECT statement of a UNION. SQLite does not
+	// allow parentheses around the operands.
+	UnionOperand(query string) string
+}
+
+var (
+	// MySQL is the default dialect of a Connection.
+	MySQL Dialect = mysqlDialect{}
+	// PostgreSQL dialect with "quoted" identifiers and $n placeholders.
+	PostgreSQL Dialect = postgresDialect{}
+	// SQLite dialect with "quoted" identifiers and ? placeholders.
+	SQLite Dialect = sqliteDialect{}
+)
+
+type (
+	mysqlDialect    struct{}
+	postgresDialect struct{}
+	sqliteDialect   struct{}
+)
+
+func (mysqlDialect) Name() string                  { return "mysql" }
+func (mysqlDialect) QuoteIdent(name string) string { return Quote + name + Quote }
+func (mysqlDialect) Placeholder(n int) string      { return "?" }
+func (mysqlDialect) LimitOffset(limit, offset uint64, hasLimit, hasOffset bool) string {
+	return limitOffset(limit, offset, hasLimit, hasOffset)
+}
+func (mysqlDialect) Insert(ignore, replace bool) string {
+	switch {
+	case replace:
+		return "REPLACE "
+	case ignore:
+		return "INSERT IGNORE "
+	}
+	return "INSERT "
+}
+func (mysqlDialect) OnConflict(conflictColumns []string, update bool) string {
+	if update {
+		return " ON DUPLICATE KEY UPDATE "
+	}
+	return ""
+}
+func (mysqlDialect) Excluded(column string) string    { return "VALUES(" + Quote + column + Quote + ")" }
+func (mysqlDialect) Now() string                      { return "NOW()" }
+func (mysqlDialect) IfNull(expr, alt string) string   { return "IFNULL(" + expr + ", " + alt + ")" }
+func (mysqlDialect) UnionOperand(query string) string { return "(" + query + ")" }
+
+func (postgresDialect) Name() string                  { return "postgres" }
+func (postgresDialect) QuoteIdent(name string) string { return quoteDouble(name) }
+func (postgresDialect) Placeholder(n int) string      { return "$" + strconv.Itoa(n) }
+func (postgresDialect) LimitOffset(limit, offset uint64, hasLimit, hasOffset bool) string {
+	return limitOffset(limit, offset, hasLimit, hasOffset)
+}
+func (postgresDialect) Insert(ignore, replace bool) string {
+	if replace {
+		panic("REPLACE is not supported by PostgreSQL, use OnDuplicateKeyUpdate")
+	}
+	return "INSERT "
+}
+func (postgresDialect) OnConflict(conflictColumns []string, update bool) string {
+	return onConflict(conflictColumns, update, true)
+}
+func (postgresDialect) Excluded(column string) string    { return "EXCLUDED." + Quote + column + Quote }
+func (postgresDialect) Now() string                      { return "NOW()" }
+func (postgresDialect) IfNull(expr, alt string) string   { return "COALESCE(" + expr + ", " + alt + ")" }
+func (postgresDialect) UnionOperand(query string) string { return "(" + query + ")" }
+
+func (sqliteDialect) Name() string                  { return "sqlite3" }
+func (sqliteDialect) QuoteIdent(name string) string { return quoteDouble(name) }
+func (sqliteDialect) Placeholder(n int) string      { return "?" }
+func (sqliteDialect) LimitOffset(limit, offset uint64, hasLimit, hasOffset bool) string {
+	if hasOffset && !hasLimit { // SQLite requires a LIMIT for an OFFSET
+		return " LIMIT -1" + limitOffset(0, offset, false, true)
+	}
+	return limitOffset(limit, offset, hasLimit, hasOffset)
+}
+func (sqliteDialect) Insert(ignore, replace bool) string {
+	switch {
+	case replace:
+		return "REPLACE "
+	case ignore:
+		return "INSERT OR IGNORE "
+	}
+	return "INSERT "
+}
+func (sqliteDialect) OnConflict(conflictColumns []string, update bool) string {
+	return onConflict(conflictColumns, update, false)
+}
+func (sqliteDialect) Excluded(column string) string    { return "excluded." + Quote + column + Quote }
+func (sqliteDialect) Now() string                      { return "CURRENT_TIMESTAMP" }
+func (sqliteDialect) IfNull(expr, alt string) string   { return "IFNULL(" + expr + ", " + alt + ")" }
+func (sqliteDialect) UnionOperand(query string) string { return query }
+
+func quoteDouble(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func limitOffset(limit, offset uint64, hasLimit, hasOffset bool) string {
+	var s string
+	if hasLimit {
+		s = " LIMIT " + strconv.FormatUint(limit, 10)
+	}
+	if hasOffset {
+		s += " OFFSET " + strconv.FormatUint(offset, 10)
+	}
+	return s
+}
+
+// onConflict creates ON CONFLICT (`a`,`b`) DO UPDATE SET or DO NOTHING.
+func onConflict(conflictColumns []string, update, ignore bool) string {
+	if !update && !ignore {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString(" ON CONFLICT ")
+	if len(conflictColumns) > 0 {
+		buf.WriteRune('(')
+		for i, c := range conflictColumns {
+			if i > 0 {
+				buf.WriteRune(',')
+			}
+			buf.WriteString(Quote + c + Quote)
+		}
+		buf.WriteString(") ")
+	} else if update {
+		panic("an upsert requires the conflict columns, see InsertBuilder.OnConflict")
+	}
+	if update {
+		buf.WriteString("DO UPDATE SET ")
+	} else {
+		buf.WriteString("DO NOTHING")
+	}
+	return buf.String()
+}
+
+// dialect returns the dialect of the connection of the session. A nil session
+// uses MySQL.
+func (sess *Session) dialect() Dialect {
+	if sess == nil || sess.cxn == nil || sess.cxn.Dialect == nil {
+		return MySQL
+	}
+	return sess.cxn.Dialect
+}
+
+// Rebind translates a MySQL flavoured query of the builders into the dialect.
+// Backtick quoted identifiers will be quoted by the dialect and the ?
+// placeholders replaced. Slice arguments, except []byte, get expanded into a
+// list of placeholders like the interpolation does: IN ? becomes IN ($1,$2).
+// Quoted string literals stay untouched. Returns the new query and the
+// flattened arguments.
+func Rebind(d Dialect, query string, args []interface{}) (string, []interface{}, error) {
+	var buf bytes.Buffer
+	var newArgs []interface{}
+	if len(args) > 0 {
+		newArgs = make([]interface{}, 0, len(args))
+	}
+	n, argPos := 0, 0
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch c {
+		case '\'', '"':
+			// copy string literals including escaped quotes
+			j := i + 1
+			for ; j < len(query); j++ {
+				if query[j] == '\\' {
+					j++
+					continue
+				}
+				if query[j] == c {
+					if j+1 < len(query) && query[j+1] == c {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			if j >= len(query) {
+				j = len(query) - 1
+			}
+			buf.WriteString(query[i : j+1])
+			i = j
+		case '`':
+			end := strings.IndexByte(query[i+1:], '`')
+			if end < 0 {
+				buf.WriteString(query[i:])
+				i = len(query)
+				continue
+			}
+			buf.WriteString(d.QuoteIdent(query[i+1 : i+1+end]))
+			i += end + 1
+		case '?':
+			if argPos >= len(args) {
+				return "", nil, ErrArgumentMismatch
+			}
+			a := args[argPos]
+			argPos++
+			v := reflect.ValueOf(a)
+			if a != nil && v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+				if v.Len() == 0 {
+					return "", nil, ErrInvalidSliceLength
+				}
+				buf.WriteRune('(')
+				for k := 0; k < v.Len(); k++ {
+					if k > 0 {
+						buf.WriteRune(',')
+					}
+					n++
+					buf.WriteString(d.Placeholder(n))
+					newArgs = append(newArgs, v.Index(k).Interface())
+				}
+				buf.WriteRune(')')
+				continue
+			}
+			n++
+			buf.WriteString(d.Placeholder(n))
+			newArgs = append(newArgs, a)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	if argPos != len(args) {
+		return "", nil, ErrArgumentMismatch
+	}
+	return buf.String(), newArgs, nil
+}
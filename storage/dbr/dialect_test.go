@@ -0,0 +1,88 @@
+package dbr
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		d        Dialect
+		query    string
+		args     []interface{}
+		wantSql  string
+		wantArgs []interface{}
+		wantErr  error
+	}{
+		{MySQL, "SELECT `a` FROM `b` WHERE `c` = ?", []interface{}{1}, "SELECT `a` FROM `b` WHERE `c` = ?", []interface{}{1}, nil},
+		{PostgreSQL, "SELECT `a` FROM `b` WHERE `c` = ? AND d IN ?", []interface{}{1, []int{2, 3}},
+			`SELECT "a" FROM "b" WHERE "c" = $1 AND d IN ($2,$3)`, []interface{}{1, 2, 3}, nil},
+		{SQLite, "SELECT `a` FROM `b` WHERE `c` = ? AND d IN ?", []interface{}{[]byte("x"), []string{"y", "z"}},
+			`SELECT "a" FROM "b" WHERE "c" = ? AND d IN (?,?)`, []interface{}{[]byte("x"), "y", "z"}, nil},
+		{PostgreSQL, "SELECT 'it''s `x` ?', \"a\\\"?\" FROM t WHERE c = ?", []interface{}{1},
+			"SELECT 'it''s `x` ?', \"a\\\"?\" FROM t WHERE c = $1", []interface{}{1}, nil},
+		{PostgreSQL, "SELECT a FROM t WHERE c = ?", nil, "", nil, ErrArgumentMismatch},
+		{PostgreSQL, "SELECT a FROM t", []interface{}{1}, "", nil, ErrArgumentMismatch},
+		{PostgreSQL, "SELECT a FROM t WHERE c IN ?", []interface{}{[]int{}}, "", nil, ErrInvalidSliceLength},
+	}
+	for i, test := range tests {
+		q, args, err := Rebind(test.d, test.query, test.args)
+		assert.Exactly(t, test.wantErr, err, "Index %d", i)
+		assert.Exactly(t, test.wantSql, q, "Index %d", i)
+		assert.Exactly(t, test.wantArgs, args, "Index %d", i)
+	}
+}
+
+func TestDialectToSql(t *testing.T) {
+	db, _ := sql.Open("dbr_stmt_test", "")
+	pg := NewConnection(db, nil).SetDialect(PostgreSQL).NewSession(nil)
+	lite := NewConnection(db, nil).SetDialect(SQLite).NewSession(nil)
+
+	sql, _ := lite.Select("a").From("b").Offset(10).ToSql()
+	assert.Equal(t, "SELECT a FROM b LIMIT -1 OFFSET 10", sql)
+	sql, _ = pg.Select("a").From("b").Offset(10).ToSql()
+	assert.Equal(t, "SELECT a FROM b OFFSET 10", sql)
+
+	sql, args := pg.InsertInto("stock").Columns("product_id", "qty").Values(1, 2).
+		OnConflict("product_id").OnDuplicateKeyUpdate("qty").ToSql()
+	assert.Equal(t, "INSERT INTO stock (`product_id`,`qty`) VALUES (?,?) ON CONFLICT (`product_id`) DO UPDATE SET `qty`=EXCLUDED.`qty`", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+	q, _, err := Rebind(PostgreSQL, sql, args)
+	assert.NoError(t, err)
+	assert.Equal(t, `INSERT INTO stock ("product_id","qty") VALUES ($1,$2) ON CONFLICT ("product_id") DO UPDATE SET "qty"=EXCLUDED."qty"`, q)
+
+	sql, _ = pg.InsertInto("stock").Columns("a").Values(1).Ignore().ToSql()
+	assert.Equal(t, "INSERT INTO stock (`a`) VALUES (?) ON CONFLICT DO NOTHING", sql)
+	sql, _ = lite.InsertInto("stock").Columns("a").Values(1).Ignore().ToSql()
+	assert.Equal(t, "INSERT OR IGNORE INTO stock (`a`) VALUES (?)", sql)
+	sql, _ = lite.InsertInto("stock").Columns("a").Values(1).OnConflict("a").OnDuplicateKeyUpdate("a").ToSql()
+	assert.Equal(t, "INSERT INTO stock (`a`) VALUES (?) ON CONFLICT (`a`) DO UPDATE SET `a`=excluded.`a`", sql)
+
+	assert.Panics(t, func() { pg.ReplaceInto("stock").Columns("a").Values(1).ToSql() })
+	assert.Panics(t, func() { pg.InsertInto("stock").Columns("a").Values(1).OnDuplicateKeyUpdate("a").ToSql() })
+
+	sql, _ = lite.UnionAll(lite.Select("a").From("b"), lite.Select("a").From("c")).Limit(5).ToSql()
+	assert.Equal(t, "SELECT a FROM b UNION ALL SELECT a FROM c LIMIT 5", sql)
+	sql, _ = pg.Union(pg.Select("a").From("b"), pg.Select("a").From("c")).ToSql()
+	assert.Equal(t, "(SELECT a FROM b) UNION (SELECT a FROM c)", sql)
+
+	assert.Equal(t, "COALESCE(`t1`.`c1`, `t2`.`c2`) AS `c`", pg.IfNullAs("t1", "c1", "t2", "c2", "c"))
+	assert.Equal(t, "IFNULL(`t1`.`c1`, `t2`.`c2`) AS `c`", lite.IfNullAs("t1", "c1", "t2", "c2", "c"))
+	assert.Equal(t, "COALESCE(a, b)", PostgreSQL.IfNull("a", "b"))
+	assert.Equal(t, "IFNULL(a, b)", MySQL.IfNull("a", "b"))
+	assert.Equal(t, "CURRENT_TIMESTAMP", SQLite.Now())
+	assert.Equal(t, "NOW()", PostgreSQL.Now())
+}
+
+func TestDialectExec(t *testing.T) {
+	db, _ := sql.Open("dbr_stmt_test", "")
+	s := NewConnection(db, nil).SetDialect(PostgreSQL).NewSession(nil)
+	stmtDriver.lastQueries()
+
+	n, err := s.Select("n").From("t").Where(In("id", []int{1, 2})).ReturnInt64()
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(1), n)
+	assert.Exactly(t, []string{`SELECT n FROM t WHERE ("id" IN ($1,$2))`}, stmtDriver.lastQueries())
+}
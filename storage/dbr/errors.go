@@ -11,4 +11,8 @@ var (
 	ErrInvalidSliceValue  = errors.New("trying to interpolate invalid slice value into query")
 	ErrInvalidValue       = errors.New("trying to interpolate invalid value into query")
 	ErrArgumentMismatch   = errors.New("mismatch between ? (placeholders) and arguments")
+	ErrNoColumns          = errors.New("no columns specified")
+	// ErrStop can be returned by the callback of Iterate() to stop the
+	// iteration without an error.
+	ErrStop = errors.New("stop iteration")
 )
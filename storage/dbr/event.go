@@ -1,5 +1,10 @@
 package dbr
 
+import (
+	"context"
+	"fmt"
+)
+
 // EventReceiver gets events from dbr methods for logging purposes
 type EventReceiver interface {
 	Event(eventName string)
@@ -10,8 +15,44 @@ type EventReceiver interface {
 	TimingKv(eventName string, nanoseconds int64, kvs map[string]string)
 }
 
+// ContextEventReceiver can be implemented additionally to EventReceiver to
+// receive the context of the query, e.g. to extract tracing information or
+// a request ID. The *Context methods will then be called instead of their
+// counterparts without context.
+type ContextEventReceiver interface {
+	EventErrKvContext(ctx context.Context, eventName string, err error, kvs map[string]string) error
+	TimingKvContext(ctx context.Context, eventName string, nanoseconds int64, kvs map[string]string)
+}
+
 type kvs map[string]string
 
+// eventErrKv calls EventErrKvContext if er implements ContextEventReceiver
+func eventErrKv(ctx context.Context, er EventReceiver, eventName string, err error, kvs map[string]string) error {
+	if cer, ok := er.(ContextEventReceiver); ok {
+		return cer.EventErrKvContext(ctx, eventName, err, kvs)
+	}
+	return er.EventErrKv(eventName, err, kvs)
+}
+
+// timingKvs returns the key/value data of a timing event. Arguments which
+// have not been interpolated into the SQL, e.g. of a prepared statement, will
+// be added as args.
+func timingKvs(sql string, args []interface{}) kvs {
+	if len(args) == 0 {
+		return kvs{"sql": sql}
+	}
+	return kvs{"sql": sql, "args": fmt.Sprint(args)}
+}
+
+// timingKv calls TimingKvContext if er implements ContextEventReceiver
+func timingKv(ctx context.Context, er EventReceiver, eventName string, nanoseconds int64, kvs map[string]string) {
+	if cer, ok := er.(ContextEventReceiver); ok {
+		cer.TimingKvContext(ctx, eventName, nanoseconds, kvs)
+		return
+	}
+	er.TimingKv(eventName, nanoseconds, kvs)
+}
+
 // NullEventReceiver is a sentinel EventReceiver; use it if the caller doesn't supply one
 type NullEventReceiver struct{}
 
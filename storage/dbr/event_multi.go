@@ -0,0 +1,87 @@
+package dbr
+
+import "context"
+
+// MultiEventReceiver forwards all events to several receivers in the order of
+// the slice, e.g. to a health stream, a SlowQueryLog and QueryStats at the
+// same time. The *Context methods will be called on receivers implementing
+// ContextEventReceiver.
+type MultiEventReceiver []EventReceiver
+
+var (
+	_ EventReceiver        = MultiEventReceiver(nil)
+	_ ContextEventReceiver = MultiEventReceiver(nil)
+)
+
+// NewMultiEventReceiver combines the receivers. Nil receivers will be
+// skipped.
+func NewMultiEventReceiver(receivers ...EventReceiver) MultiEventReceiver {
+	m := make(MultiEventReceiver, 0, len(receivers))
+	for _, r := range receivers {
+		if r != nil {
+			m = append(m, r)
+		}
+	}
+	return m
+}
+
+// Event forwards a simple notification
+func (m MultiEventReceiver) Event(eventName string) {
+	for _, r := range m {
+		r.Event(eventName)
+	}
+}
+
+// EventKv forwards a notification along with key/value data
+func (m MultiEventReceiver) EventKv(eventName string, kvs map[string]string) {
+	for _, r := range m {
+		r.EventKv(eventName, kvs)
+	}
+}
+
+// EventErr forwards an error and returns the error of the first receiver.
+func (m MultiEventReceiver) EventErr(eventName string, err error) error {
+	ret := err
+	for i, r := range m {
+		if e := r.EventErr(eventName, err); i == 0 {
+			ret = e
+		}
+	}
+	return ret
+}
+
+// EventErrKv forwards an error along with key/value data and returns the
+// error of the first receiver.
+func (m MultiEventReceiver) EventErrKv(eventName string, err error, kvs map[string]string) error {
+	return m.EventErrKvContext(context.Background(), eventName, err, kvs)
+}
+
+// EventErrKvContext same as EventErrKv including the context of the query.
+func (m MultiEventReceiver) EventErrKvContext(ctx context.Context, eventName string, err error, kvs map[string]string) error {
+	ret := err
+	for i, r := range m {
+		if e := eventErrKv(ctx, r, eventName, err, kvs); i == 0 {
+			ret = e
+		}
+	}
+	return ret
+}
+
+// Timing forwards the time an event took
+func (m MultiEventReceiver) Timing(eventName string, nanoseconds int64) {
+	for _, r := range m {
+		r.Timing(eventName, nanoseconds)
+	}
+}
+
+// TimingKv forwards the time an event took along with key/value data
+func (m MultiEventReceiver) TimingKv(eventName string, nanoseconds int64, kvs map[string]string) {
+	m.TimingKvContext(context.Background(), eventName, nanoseconds, kvs)
+}
+
+// TimingKvContext same as TimingKv including the context of the query.
+func (m MultiEventReceiver) TimingKvContext(ctx context.Context, eventName string, nanoseconds int64, kvs map[string]string) {
+	for _, r := range m {
+		timingKv(ctx, r, eventName, nanoseconds, kvs)
+	}
+}
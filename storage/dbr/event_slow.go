@@ -0,0 +1,82 @@
+package dbr
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/corestoreio/csfw/utils/log"
+)
+
+// SlowQueryLog is an EventReceiver which logs all timing events whose
+// duration exceeds the Threshold with the level warn. The log entry contains
+// the event name, the duration, the caller outside of dbr and the key/value
+// data of the event, e.g. the SQL. Arguments are part of the SQL if they have
+// been interpolated, otherwise they are logged as args. Errors will be passed
+// through.
+//
+//	cxn := dbr.NewConnection(db, dbr.NewSlowQueryLog(200*time.Millisecond))
+type SlowQueryLog struct {
+	NullEventReceiver
+	Threshold time.Duration
+	// Logger overrides the global logger of package utils/log if not nil.
+	Logger log.Logger
+}
+
+// NewSlowQueryLog creates a new slow query logger for the threshold.
+func NewSlowQueryLog(threshold time.Duration) *SlowQueryLog {
+	return &SlowQueryLog{Threshold: threshold}
+}
+
+// Timing logs the event if it exceeds the threshold.
+func (l *SlowQueryLog) Timing(eventName string, nanoseconds int64) {
+	l.TimingKv(eventName, nanoseconds, nil)
+}
+
+// TimingKv logs the event and its key/value data if it exceeds the threshold.
+func (l *SlowQueryLog) TimingKv(eventName string, nanoseconds int64, kvs map[string]string) {
+	d := time.Duration(nanoseconds)
+	if d < l.Threshold {
+		return
+	}
+	args := make([]interface{}, 0, 6+len(kvs)*2)
+	args = append(args, "event", eventName, "duration", d.String(), "caller", caller())
+
+	keys := make([]string, 0, len(kvs))
+	for k := range kvs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, k, kvs[k])
+	}
+
+	if l.Logger != nil {
+		l.Logger.Warn("dbr.SlowQueryLog", args...)
+		return
+	}
+	log.Warn("dbr.SlowQueryLog", args...)
+}
+
+var dbrPkgPath = reflect.TypeOf(SlowQueryLog{}).PkgPath() + "."
+
+// caller returns file:line of the first function on the stack outside of
+// package dbr.
+func caller() string {
+	pc := make([]uintptr, 32)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	for {
+		f, more := frames.Next()
+		inDbr := strings.HasPrefix(f.Function, dbrPkgPath) && !strings.HasSuffix(f.File, "_test.go")
+		if !inDbr && !strings.HasPrefix(f.Function, "runtime.") {
+			return f.File + ":" + strconv.Itoa(f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package dbr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets upper bounds in seconds of the latency histogram of
+// QueryStats.
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// QueryStatsMaxQueries maximum number of distinct statements per event in
+// QueryStats. Further statements will be counted as "other".
+var QueryStatsMaxQueries = 1000
+
+// QueryStats is an EventReceiver which aggregates the count and a latency
+// histogram of the timing events per event name and normalized statement, see
+// NormalizeQuery(). Errors will be counted per event name. QueryStats
+// implements http.Handler and exports the statistics in the Prometheus text
+// format:
+//
+//	stats := dbr.NewQueryStats()
+//	cxn := dbr.NewConnection(db, stats)
+//	http.Handle("/metrics/dbr", stats)
+type QueryStats struct {
+	NullEventReceiver
+	buckets []float64
+
+	mu      sync.Mutex
+	queries map[statKey]*queryStat
+	errors  map[string]uint64
+}
+
+type statKey struct {
+	event string
+	query string
+}
+
+type queryStat struct {
+	count   uint64
+	sum     float64  // seconds
+	buckets []uint64 // not cumulative
+}
+
+// NewQueryStats creates a new statistic. Without buckets the
+// DefaultLatencyBuckets will be used. Buckets are upper bounds in seconds.
+func NewQueryStats(buckets ...float64) *QueryStats {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &QueryStats{
+		buckets: b,
+		queries: make(map[statKey]*queryStat),
+		errors:  make(map[string]uint64),
+	}
+}
+
+// Timing records an event without SQL.
+func (s *QueryStats) Timing(eventName string, nanoseconds int64) {
+	s.TimingKv(eventName, nanoseconds, nil)
+}
+
+// TimingKv records the duration of the normalized SQL of the event.
+func (s *QueryStats) TimingKv(eventName string, nanoseconds int64, kvs map[string]string) {
+	k := statKey{event: eventName, query: NormalizeQuery(kvs["sql"])}
+	sec := float64(nanoseconds) / 1e9
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	qs, ok := s.queries[k]
+	if !ok {
+		if s.countEvent(eventName) >= QueryStatsMaxQueries {
+			k.query = "other"
+			qs = s.queries[k]
+		}
+		if qs == nil {
+			qs = &queryStat{buckets: make([]uint64, len(s.buckets))}
+			s.queries[k] = qs
+		}
+	}
+	qs.count++
+	qs.sum += sec
+	if i := sort.SearchFloat64s(s.buckets, sec); i < len(s.buckets) {
+		qs.buckets[i]++
+	}
+}
+
+// countEvent returns the number of statements of an event. Must be called
+// with the lock held.
+func (s *QueryStats) countEvent(eventName string) int {
+	n := 0
+	for k := range s.queries {
+		if k.event == eventName {
+			n++
+		}
+	}
+	return n
+}
+
+// EventErr counts the error.
+func (s *QueryStats) EventErr(eventName string, err error) error {
+	return s.EventErrKv(eventName, err, nil)
+}
+
+// EventErrKv counts the error.
+func (s *QueryStats) EventErrKv(eventName string, err error, kvs map[string]string) error {
+	s.mu.Lock()
+	s.errors[eventName]++
+	s.mu.Unlock()
+	return err
+}
+
+// Reset removes all statistics.
+func (s *QueryStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = make(map[statKey]*queryStat)
+	s.errors = make(map[string]uint64)
+}
+
+// WriteTo writes the statistics in the Prometheus text format.
+func (s *QueryStats) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	s.mu.Lock()
+	keys := make([]statKey, 0, len(s.queries))
+	for k := range s.queries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].event != keys[j].event {
+			return keys[i].event < keys[j].event
+		}
+		return keys[i].query < keys[j].query
+	})
+
+	buf.WriteString("# HELP dbr_query_duration_seconds Latency of the SQL statements.\n")
+	buf.WriteString("# TYPE dbr_query_duration_seconds histogram\n")
+	for _, k := range keys {
+		qs := s.queries[k]
+		labels := `event="` + escapeLabel(k.event) + `",query="` + escapeLabel(k.query) + `"`
+		var cum uint64
+		for i, le := range s.buckets {
+			cum += qs.buckets[i]
+			fmt.Fprintf(&buf, "dbr_query_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(le, 'g', -1, 64), cum)
+		}
+		fmt.Fprintf(&buf, "dbr_query_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, qs.count)
+		fmt.Fprintf(&buf, "dbr_query_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(qs.sum, 'g', -1, 64))
+		fmt.Fprintf(&buf, "dbr_query_duration_seconds_count{%s} %d\n", labels, qs.count)
+	}
+
+	events := make([]string, 0, len(s.errors))
+	for e := range s.errors {
+		events = append(events, e)
+	}
+	sort.Strings(events)
+	buf.WriteString("# HELP dbr_errors_total Number of errors per event.\n")
+	buf.WriteString("# TYPE dbr_errors_total counter\n")
+	for _, e := range events {
+		fmt.Fprintf(&buf, "dbr_errors_total{event=\"%s\"} %d\n", escapeLabel(e), s.errors[e])
+	}
+	s.mu.Unlock()
+
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP writes the statistics in the Prometheus text format.
+func (s *QueryStats) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	s.WriteTo(w)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+var (
+	reNumber    = regexp.MustCompile(`\b\d+(\.\d+)?([eE][-+]?\d+)?\b`)
+	reSpace     = regexp.MustCompile(`\s+`)
+	reList      = regexp.MustCompile(`\(\s*\?(\s*,\s*\?)*\s*\)`)
+	reValueRows = regexp.MustCompile(`\(\?\)(\s*,\s*\(\?\))+`)
+)
+
+// NormalizeQuery replaces the literals of an SQL statement with ? and
+// collapses lists of placeholders to get one statement for all queries which
+// only differ in their arguments:
+//
+//	SELECT * FROM t WHERE id IN (1,2,3) AND name = 'x'
+//	SELECT * FROM t WHERE id IN (?) AND name = ?
+//
+// Identifiers in backticks stay untouched.
+func NormalizeQuery(query string) string {
+	if query == "" {
+		return ""
+	}
+	var buf bytes.Buffer
+	var plain bytes.Buffer // part outside of quotes
+	flush := func() {
+		buf.WriteString(reNumber.ReplaceAllString(plain.String(), "?"))
+		plain.Reset()
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch c {
+		case '\'', '"', '`':
+			j := i + 1
+			for ; j < len(query); j++ {
+				if query[j] == '\\' && c != '`' {
+					j++
+					continue
+				}
+				if query[j] == c {
+					if j+1 < len(query) && query[j+1] == c {
+						j++
+						continue
+					}
+					break
+				}
+			}
+			if j >= len(query) {
+				j = len(query) - 1
+			}
+			flush()
+			if c == '`' {
+				buf.WriteString(query[i : j+1])
+			} else {
+				buf.WriteByte('?')
+			}
+			i = j
+		default:
+			plain.WriteByte(c)
+		}
+	}
+	flush()
+
+	q := reSpace.ReplaceAllString(strings.TrimSpace(buf.String()), " ")
+	q = reList.ReplaceAllString(q, "(?)")
+	return reValueRows.ReplaceAllString(q, "(?)")
+}
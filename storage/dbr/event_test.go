@@ -0,0 +1,138 @@
+package dbr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/csfw/utils/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type warnLogger struct {
+	log.NullLogger
+	msgs []string
+	args [][]interface{}
+}
+
+func (l *warnLogger) Warn(msg string, args ...interface{}) {
+	l.msgs = append(l.msgs, msg)
+	l.args = append(l.args, args)
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	wl := &warnLogger{}
+	sl := NewSlowQueryLog(100 * time.Millisecond)
+	sl.Logger = wl
+
+	s := NewConnection(nil, sl).NewSession(nil)
+	timingKv(context.Background(), s.EventReceiver, "dbr.select", int64(10*time.Millisecond), kvs{"sql": "SELECT 1"})
+	assert.Len(t, wl.msgs, 0)
+
+	timingKv(context.Background(), s.EventReceiver, "dbr.select", int64(time.Second), kvs{"sql": "SELECT 2", "args": "[1]"})
+	if assert.Len(t, wl.args, 1) {
+		args := wl.args[0]
+		assert.Exactly(t, []interface{}{"event", "dbr.select", "duration", "1s", "caller"}, args[:5])
+		assert.Contains(t, args[5], "event_test.go:")
+		assert.Exactly(t, []interface{}{"args", "[1]", "sql", "SELECT 2"}, args[6:])
+	}
+
+	errTest := errors.New("test")
+	assert.Exactly(t, errTest, sl.EventErrKv("dbr.select", errTest, nil))
+}
+
+func TestSlowQueryLogPreparedArgs(t *testing.T) {
+	wl := &warnLogger{}
+	sl := NewSlowQueryLog(0)
+	sl.Logger = wl
+
+	db, err := sql.Open("dbr_stmt_test", "")
+	assert.NoError(t, err)
+	s := NewConnection(db, sl).EnableStmtCache(2).NewSession(nil)
+	_, err = s.Select("n").From("t").Where("id = ?", 7).ReturnInt64()
+	assert.NoError(t, err)
+
+	if assert.Len(t, wl.args, 1) {
+		args := wl.args[0]
+		assert.Exactly(t, []interface{}{"args", "[7]", "sql", "SELECT n FROM t WHERE (id = ?)"}, args[6:])
+	}
+}
+
+func TestQueryStats(t *testing.T) {
+	qs := NewQueryStats(0.01, 0.1)
+	qs.TimingKv("dbr.select", int64(5*time.Millisecond), kvs{"sql": "SELECT * FROM `t1` WHERE id IN (1,2,3) AND name = 'x'"})
+	qs.TimingKv("dbr.select", int64(50*time.Millisecond), kvs{"sql": "SELECT * FROM `t1` WHERE id IN (4) AND name = 'y'"})
+	qs.TimingKv("dbr.select", int64(time.Second), kvs{"sql": "SELECT * FROM `t1` WHERE id IN (5,6) AND name = \"z\""})
+	qs.EventErrKv("dbr.select.load_all.query", errors.New("test"), nil)
+
+	rec := httptest.NewRecorder()
+	qs.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Exactly(t, "text/plain; version=0.0.4", rec.Header().Get("Content-Type"))
+
+	labels := `event="dbr.select",query="SELECT * FROM ` + "`t1`" + ` WHERE id IN (?) AND name = ?"`
+	want := []string{
+		"# TYPE dbr_query_duration_seconds histogram",
+		"dbr_query_duration_seconds_bucket{" + labels + `,le="0.01"} 1`,
+		"dbr_query_duration_seconds_bucket{" + labels + `,le="0.1"} 2`,
+		"dbr_query_duration_seconds_bucket{" + labels + `,le="+Inf"} 3`,
+		"dbr_query_duration_seconds_sum{" + labels + "} 1.055",
+		"dbr_query_duration_seconds_count{" + labels + "} 3",
+		`dbr_errors_total{event="dbr.select.load_all.query"} 1`,
+	}
+	body := rec.Body.String()
+	for _, w := range want {
+		assert.Contains(t, body, w+"\n")
+	}
+
+	qs.Reset()
+	rec = httptest.NewRecorder()
+	qs.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.NotContains(t, rec.Body.String(), "dbr.select")
+}
+
+func TestQueryStatsMaxQueries(t *testing.T) {
+	defer func(n int) { QueryStatsMaxQueries = n }(QueryStatsMaxQueries)
+	QueryStatsMaxQueries = 2
+
+	qs := NewQueryStats()
+	for _, tbl := range []string{"a", "b", "c", "d"} {
+		qs.TimingKv("dbr.select", 1, kvs{"sql": "SELECT * FROM " + tbl})
+	}
+	assert.Len(t, qs.queries, 3)
+	assert.Exactly(t, uint64(2), qs.queries[statKey{"dbr.select", "other"}].count)
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		have, want string
+	}{
+		{"", ""},
+		{"SELECT a1, b_2 FROM `t 1` WHERE x = 1.5e3 AND y = 'it''s' AND z = 'a\\'b'", "SELECT a1, b_2 FROM `t 1` WHERE x = ? AND y = ? AND z = ?"},
+		{"INSERT INTO t (`a`,`b`) VALUES (1,'x'),(2,'y'),\n (3, 'z')", "INSERT INTO t (`a`,`b`) VALUES (?)"},
+		{"SELECT *   FROM t\n\tLIMIT 10 OFFSET 20", "SELECT * FROM t LIMIT ? OFFSET ?"},
+	}
+	for _, test := range tests {
+		assert.Exactly(t, test.want, NormalizeQuery(test.have), test.have)
+	}
+}
+
+func TestMultiEventReceiver(t *testing.T) {
+	qs := NewQueryStats()
+	r := &stmtTestReceiver{events: make(map[string]int)}
+	m := NewMultiEventReceiver(nil, r, qs)
+	assert.Len(t, m, 2)
+
+	s := NewConnection(nil, m).NewSession(nil)
+	timingKv(context.Background(), s.EventReceiver, "dbr.select", 1, kvs{"sql": "SELECT 1"})
+	errTest := errors.New("test")
+	assert.Exactly(t, errTest, eventErrKv(context.Background(), s.EventReceiver, "dbr.select.err", errTest, nil))
+	m.EventKv("dbr.ping", nil)
+
+	assert.Exactly(t, 1, r.events["dbr.select"])
+	assert.Exactly(t, 1, r.events["dbr.ping"])
+	assert.Exactly(t, uint64(1), qs.errors["dbr.select.err"])
+	assert.Len(t, qs.queries, 1)
+}
@@ -2,6 +2,7 @@ package dbr
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
@@ -9,16 +10,36 @@ import (
 	"time"
 )
 
+// MaxAllowedPacket default maximum size in bytes of an interpolated INSERT
+// statement. Larger Values/Record sets will be split into several statements
+// by Exec(). Should match the MySQL server variable max_allowed_packet.
+var MaxAllowedPacket = 4 << 20
+
+// maxPlaceholders maximum number of placeholders in a prepared statement.
+const maxPlaceholders = 65535
+
 // InsertBuilder contains the clauses for an INSERT statement
 type InsertBuilder struct {
 	*Session
 	runner
 
+	IsReplace bool
+	IsIgnore  bool
+
 	Into string
 	Cols []string
 	Vals [][]interface{}
 	Recs []interface{}
 	Maps map[string]interface{}
+	// Select for INSERT INTO ... SELECT. Cols can be empty.
+	Select *SelectBuilder
+	// OnDuplicateKeys contains the assignments of ON DUPLICATE KEY UPDATE
+	OnDuplicateKeys []*setClause
+	// ConflictColumns unique key columns for the upsert of PostgreSQL and
+	// SQLite, ignored by MySQL.
+	ConflictColumns []string
+	// MaxPacketSize overrides MaxAllowedPacket if > 0.
+	MaxPacketSize int
 }
 
 // InsertInto instantiates a InsertBuilder for the given table
@@ -39,6 +60,75 @@ func (tx *Tx) InsertInto(into string) *InsertBuilder {
 	}
 }
 
+// ReplaceInto instantiates a InsertBuilder for a REPLACE statement
+func (sess *Session) ReplaceInto(into string) *InsertBuilder {
+	b := sess.InsertInto(into)
+	b.IsReplace = true
+	return b
+}
+
+// ReplaceInto instantiates a InsertBuilder for a REPLACE statement bound to
+// a transaction
+func (tx *Tx) ReplaceInto(into string) *InsertBuilder {
+	b := tx.InsertInto(into)
+	b.IsReplace = true
+	return b
+}
+
+// Ignore creates an INSERT IGNORE statement. Rows with duplicate keys will
+// be skipped.
+func (b *InsertBuilder) Ignore() *InsertBuilder {
+	b.IsIgnore = true
+	return b
+}
+
+// OnDuplicateKeyUpdate appends for each column the assignment
+// `col`=VALUES(`col`) to the ON DUPLICATE KEY UPDATE clause. Other dialects
+// create the equivalent ON CONFLICT ... DO UPDATE SET clause.
+func (b *InsertBuilder) OnDuplicateKeyUpdate(columns ...string) *InsertBuilder {
+	for _, c := range columns {
+		b.OnDuplicateKeys = append(b.OnDuplicateKeys, &setClause{column: c, value: Expr(b.dialect().Excluded(c))})
+	}
+	return b
+}
+
+// OnConflict sets the unique key columns which PostgreSQL and SQLite require
+// for an upsert: ON CONFLICT (`col`, ...). MySQL ignores them.
+func (b *InsertBuilder) OnConflict(columns ...string) *InsertBuilder {
+	b.ConflictColumns = columns
+	return b
+}
+
+// OnDuplicateKeySet appends an assignment to the ON DUPLICATE KEY UPDATE
+// clause. The value can be an Expr(), e.g.
+//
+//	OnDuplicateKeySet("qty", dbr.Expr("`qty` + VALUES(`qty`)"))
+func (b *InsertBuilder) OnDuplicateKeySet(column string, value interface{}) *InsertBuilder {
+	if dbVal, ok := value.(driver.Valuer); ok {
+		if val, err := dbVal.Value(); err == nil {
+			value = val
+		} else {
+			panic(err)
+		}
+	}
+	b.OnDuplicateKeys = append(b.OnDuplicateKeys, &setClause{column: column, value: value})
+	return b
+}
+
+// FromSelect creates an INSERT INTO ... SELECT statement. Columns are
+// optional.
+func (b *InsertBuilder) FromSelect(sb *SelectBuilder) *InsertBuilder {
+	b.Select = sb
+	return b
+}
+
+// MaxPacket sets the maximum size in bytes of one interpolated statement.
+// See MaxAllowedPacket.
+func (b *InsertBuilder) MaxPacket(size int) *InsertBuilder {
+	b.MaxPacketSize = size
+	return b
+}
+
 // Columns appends columns to insert in the statement
 func (b *InsertBuilder) Columns(columns ...string) *InsertBuilder {
 	b.Cols = columns
@@ -56,6 +146,8 @@ func (b *InsertBuilder) Values(vals ...interface{}) *InsertBuilder {
 }
 
 // Record pulls in values to match Columns from the record. Uses reflection.
+// Without Columns the columns will be derived from the first record: fields
+// tagged with db:"-" and zero fields tagged with db:",omitempty" are skipped.
 func (b *InsertBuilder) Record(record interface{}) *InsertBuilder {
 	b.Recs = append(b.Recs, record)
 	return b
@@ -96,6 +188,13 @@ func (b *InsertBuilder) ToSql() (string, []interface{}) {
 	if len(b.Into) == 0 {
 		panic("no table specified")
 	}
+	if b.IsReplace && len(b.OnDuplicateKeys) > 0 {
+		panic("REPLACE cannot have an ON DUPLICATE KEY UPDATE clause")
+	}
+	if b.Select != nil {
+		return b.selectToSql()
+	}
+	b.deriveColumns()
 	if len(b.Cols) == 0 && len(b.Maps) == 0 {
 		panic("no columns or map specified")
 	} else if len(b.Maps) == 0 {
@@ -107,62 +206,125 @@ func (b *InsertBuilder) ToSql() (string, []interface{}) {
 		}
 	}
 
-	var sql bytes.Buffer
-
-	sql.WriteString("INSERT INTO ")
-	sql.WriteString(b.Into)
-	sql.WriteString(" (")
-
 	if len(b.Maps) != 0 {
-		return b.MapToSql(sql)
+		var sql bytes.Buffer
+		b.writeInto(&sql)
+		sql.WriteString(" (")
+		q, args := b.MapToSql(sql)
+		return b.onDuplicateToSql(q, args)
 	}
+	return b.rowsToSql(b.rows())
+}
 
-	var args []interface{}
-	var placeholder bytes.Buffer // Build the placeholder like "(?,?,?)"
-	// Simulataneously write the cols to the sql buffer, and build a placeholder
+// deriveColumns sets the columns from the first record if Record() has been
+// used without Columns().
+func (b *InsertBuilder) deriveColumns() {
+	if len(b.Cols) == 0 && len(b.Recs) > 0 {
+		ind := reflect.Indirect(reflect.ValueOf(b.Recs[0]))
+		b.Cols = getStructInfo(ind.Type()).columns(ind)
+	}
+}
+
+// writeInto writes INSERT [IGNORE] INTO table or REPLACE INTO table
+func (b *InsertBuilder) writeInto(sql *bytes.Buffer) {
+	sql.WriteString(b.dialect().Insert(b.IsIgnore, b.IsReplace))
+	sql.WriteString("INTO ")
+	sql.WriteString(b.Into)
+}
+
+// writeColumns writes the quoted columns and returns the placeholder for
+// one row like "(?,?,?)"
+func (b *InsertBuilder) writeColumns(sql *bytes.Buffer) string {
+	var placeholder bytes.Buffer
+	sql.WriteString(" (")
 	placeholder.WriteRune('(')
 	for i, c := range b.Cols {
 		if i > 0 {
 			sql.WriteRune(',')
 			placeholder.WriteRune(',')
 		}
-		Quoter.writeQuotedColumn(c, &sql)
+		Quoter.writeQuotedColumn(c, sql)
 		placeholder.WriteRune('?')
 	}
-	sql.WriteString(") VALUES ")
+	sql.WriteRune(')')
 	placeholder.WriteRune(')')
-	placeholderStr := placeholder.String()
+	return placeholder.String()
+}
+
+// rows returns the values of Vals and Recs. Panics if a record cannot be
+// reflected.
+func (b *InsertBuilder) rows() [][]interface{} {
+	rows := make([][]interface{}, 0, len(b.Vals)+len(b.Recs))
+	rows = append(rows, b.Vals...)
+	for _, rec := range b.Recs {
+		ind := reflect.Indirect(reflect.ValueOf(rec))
+		vals, err := b.valuesFor(ind.Type(), ind, b.Cols)
+		if err != nil {
+			panic(err.Error())
+		}
+		rows = append(rows, vals)
+	}
+	return rows
+}
+
+// rowsToSql creates the statement for a set of rows.
+func (b *InsertBuilder) rowsToSql(rows [][]interface{}) (string, []interface{}) {
+	var sql bytes.Buffer
+	var args []interface{}
+
+	b.writeInto(&sql)
+	placeholderStr := b.writeColumns(&sql)
+	sql.WriteString(" VALUES ")
 
 	// Go thru each value we want to insert. Write the placeholders, and collect args
-	for i, row := range b.Vals {
+	for i, row := range rows {
 		if i > 0 {
 			sql.WriteRune(',')
 		}
 		sql.WriteString(placeholderStr)
+		args = append(args, row...)
+	}
+	return b.onDuplicateToSql(sql.String(), args)
+}
 
-		for _, v := range row {
-			args = append(args, v)
-		}
+// selectToSql creates the statement for INSERT INTO ... SELECT
+func (b *InsertBuilder) selectToSql() (string, []interface{}) {
+	var sql bytes.Buffer
+	b.writeInto(&sql)
+	if len(b.Cols) > 0 {
+		b.writeColumns(&sql)
 	}
-	anyVals := len(b.Vals) > 0
+	q, args := b.Select.ToSql()
+	sql.WriteRune(' ')
+	sql.WriteString(q)
+	return b.onDuplicateToSql(sql.String(), args)
+}
 
-	// Go thru the records. Write the placeholders, and do reflection on the records to extract args
-	for i, rec := range b.Recs {
-		if i > 0 || anyVals {
-			sql.WriteRune(',')
+// onDuplicateToSql appends the ON DUPLICATE KEY UPDATE clause or the
+// equivalent of the dialect.
+func (b *InsertBuilder) onDuplicateToSql(q string, args []interface{}) (string, []interface{}) {
+	if len(b.OnDuplicateKeys) == 0 {
+		if b.IsIgnore {
+			q += b.dialect().OnConflict(b.ConflictColumns, false)
 		}
-		sql.WriteString(placeholderStr)
-
-		ind := reflect.Indirect(reflect.ValueOf(rec))
-		vals, err := b.valuesFor(ind.Type(), ind, b.Cols)
-		if err != nil {
-			panic(err.Error())
+		return q, args
+	}
+	sql := bytes.NewBufferString(q)
+	sql.WriteString(b.dialect().OnConflict(b.ConflictColumns, true))
+	for i, c := range b.OnDuplicateKeys {
+		if i > 0 {
+			sql.WriteString(", ")
 		}
-		for _, v := range vals {
-			args = append(args, v)
+		Quoter.writeQuotedColumn(c.column, sql)
+		if e, ok := c.value.(*expr); ok {
+			sql.WriteRune('=')
+			sql.WriteString(e.Sql)
+			args = append(args, e.Values...)
+		} else {
+			sql.WriteString("=?")
+			args = append(args, c.value)
 		}
 	}
-
 	return sql.String(), args
 }
 
@@ -209,23 +371,73 @@ func (b *InsertBuilder) MapToSql(sql bytes.Buffer) (string, []interface{}) {
 	return sql.String(), args
 }
 
+// Chunks splits the rows of Vals and Recs into sets whose interpolated
+// statement does not exceed MaxAllowedPacket or MaxPacketSize and which
+// contain at most 65535 placeholders. Returns nil if the statement does not
+// need to be split or has no rows and ErrNoColumns if no columns are known.
+func (b *InsertBuilder) Chunks() ([][][]interface{}, error) {
+	if b.Select != nil || len(b.Maps) > 0 || len(b.Vals)+len(b.Recs) < 2 {
+		return nil, nil
+	}
+	b.deriveColumns()
+	if len(b.Cols) == 0 {
+		return nil, ErrNoColumns
+	}
+	max := b.MaxPacketSize
+	if max <= 0 {
+		max = MaxAllowedPacket
+	}
+	rows := b.rows()
+	head, _ := b.rowsToSql(nil)
+	placeholderStr := b.writeColumns(new(bytes.Buffer))
+
+	var chunks [][][]interface{}
+	start, size, args := 0, len(head), 0
+	for i, row := range rows {
+		r, err := Interpolate(placeholderStr, row)
+		if err != nil {
+			return nil, err
+		}
+		if i > start && (size+len(r)+1 > max || args+len(row) > maxPlaceholders) {
+			chunks = append(chunks, rows[start:i])
+			start, size, args = i, len(head), 0
+		}
+		size += len(r) + 1
+		args += len(row)
+	}
+	if start == 0 {
+		return nil, nil
+	}
+	return append(chunks, rows[start:]), nil
+}
+
 // Exec executes the statement represented by the InsertBuilder
 // It returns the raw database/sql Result and an error if there was one
 func (b *InsertBuilder) Exec() (sql.Result, error) {
-	sql, args := b.ToSql()
+	return b.ExecContext(context.Background())
+}
+
+// ExecContext executes the statement represented by the InsertBuilder. The
+// statement gets cancelled when the context is done. The default timeout of
+// the session applies if the context has no deadline. Large Values/Record
+// sets will be split into several statements, see Chunks(). Those statements
+// are not atomic unless the builder is bound to a transaction.
+func (b *InsertBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
 
-	fullSql, err := Interpolate(sql, args)
+	chunks, err := b.Chunks()
 	if err != nil {
-		return nil, b.EventErrKv("dbr.insert.exec.interpolate", err, kvs{"sql": sql, "args": fmt.Sprint(args)})
+		return nil, eventErrKv(ctx, b.EventReceiver, "dbr.insert.exec.chunks", err, kvs{"table": b.Into})
+	}
+	if len(chunks) > 0 {
+		return b.execChunks(ctx, chunks)
 	}
 
-	// Start the timer:
-	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.insert", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
-
-	result, err := b.runner.Exec(fullSql)
+	sql, args := b.ToSql()
+	result, fullSql, err := b.exec(ctx, sql, args)
 	if err != nil {
-		return result, b.EventErrKv("dbr.insert.exec.exec", err, kvs{"sql": fullSql})
+		return result, err
 	}
 
 	// If the structure has an "Id" field which is an int64, set it from the LastInsertId(). Otherwise, don't bother.
@@ -238,7 +450,7 @@ func (b *InsertBuilder) Exec() (sql.Result, error) {
 				if lastID, err := result.LastInsertId(); err == nil {
 					idField.Set(reflect.ValueOf(lastID))
 				} else {
-					b.EventErrKv("dbr.insert.exec.last_inserted_id", err, kvs{"sql": fullSql})
+					eventErrKv(ctx, b.EventReceiver, "dbr.insert.exec.last_inserted_id", err, kvs{"sql": fullSql})
 				}
 			}
 		}
@@ -246,3 +458,56 @@ func (b *InsertBuilder) Exec() (sql.Result, error) {
 
 	return result, nil
 }
+
+// execChunks executes one statement per chunk. The result contains the
+// LastInsertId of the first chunk and the sum of the affected rows.
+func (b *InsertBuilder) execChunks(ctx context.Context, chunks [][][]interface{}) (sql.Result, error) {
+	res := &chunkResult{}
+	for i, rows := range chunks {
+		sql, args := b.rowsToSql(rows)
+		r, _, err := b.exec(ctx, sql, args)
+		if err != nil {
+			return res, err
+		}
+		if i == 0 {
+			res.lastID, res.lastIDErr = r.LastInsertId()
+		}
+		n, err := r.RowsAffected()
+		if err != nil {
+			res.affectedErr = err
+		}
+		res.affected += n
+	}
+	return res, nil
+}
+
+// exec runs one statement and returns additionally the SQL for logging.
+func (b *InsertBuilder) exec(ctx context.Context, sql string, args []interface{}) (sql.Result, string, error) {
+	fullSql, args, prepared, err := b.interpolate(sql, args)
+	if err != nil {
+		return nil, "", eventErrKv(ctx, b.EventReceiver, "dbr.insert.exec.interpolate", err, kvs{"sql": sql, "args": fmt.Sprint(args)})
+	}
+
+	// Start the timer:
+	startTime := time.Now()
+	defer func() {
+		timingKv(ctx, b.EventReceiver, "dbr.insert", time.Since(startTime).Nanoseconds(), timingKvs(fullSql, args))
+	}()
+
+	result, err := b.execContext(ctx, b.runner, fullSql, args, prepared)
+	if err != nil {
+		return result, fullSql, eventErrKv(ctx, b.EventReceiver, "dbr.insert.exec.exec", err, kvs{"sql": fullSql})
+	}
+	return result, fullSql, nil
+}
+
+// chunkResult combines the results of a chunked insert.
+type chunkResult struct {
+	lastID      int64
+	lastIDErr   error
+	affected    int64
+	affectedErr error
+}
+
+func (r *chunkResult) LastInsertId() (int64, error) { return r.lastID, r.lastIDErr }
+func (r *chunkResult) RowsAffected() (int64, error) { return r.affected, r.affectedErr }
@@ -114,3 +114,141 @@ func validateInsertingBarack(t *testing.T, s *Session, res sql.Result, err error
 }
 
 // TODO: do a real test inserting multiple records
+
+func TestInsertIgnoreReplaceToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args := s.InsertInto("a").Ignore().Columns("b", "c").Values(1, 2).ToSql()
+	assert.Equal(t, "INSERT IGNORE INTO a (`b`,`c`) VALUES (?,?)", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	sql, args = s.ReplaceInto("a").Columns("b", "c").Values(1, 2).Values(3, 4).ToSql()
+	assert.Equal(t, "REPLACE INTO a (`b`,`c`) VALUES (?,?),(?,?)", sql)
+	assert.Equal(t, []interface{}{1, 2, 3, 4}, args)
+
+	assert.Panics(t, func() {
+		s.ReplaceInto("a").Columns("b").Values(1).OnDuplicateKeyUpdate("b").ToSql()
+	})
+}
+
+func TestInsertOnDuplicateKeyToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args := s.InsertInto("catalog_product_entity_varchar").
+		Columns("entity_id", "attribute_id", "store_id", "value").
+		Values(1, 71, 0, "Shirt").
+		Values(2, 71, 0, "Pants").
+		OnDuplicateKeyUpdate("value").
+		OnDuplicateKeySet("updated", Expr("NOW()")).
+		OnDuplicateKeySet("qty", Expr("`qty` + ?", 5)).
+		OnDuplicateKeySet("note", NullString{}).
+		ToSql()
+	assert.Equal(t, "INSERT INTO catalog_product_entity_varchar (`entity_id`,`attribute_id`,`store_id`,`value`) VALUES (?,?,?,?),(?,?,?,?) "+
+		"ON DUPLICATE KEY UPDATE `value`=VALUES(`value`), `updated`=NOW(), `qty`=`qty` + ?, `note`=?", sql)
+	assert.Equal(t, []interface{}{1, 71, 0, "Shirt", 2, 71, 0, "Pants", 5, nil}, args)
+
+	sql, args = s.InsertInto("a").Map(map[string]interface{}{"b": 1}).OnDuplicateKeyUpdate("b").ToSql()
+	assert.Equal(t, "INSERT INTO a (`b`) VALUES (?) ON DUPLICATE KEY UPDATE `b`=VALUES(`b`)", sql)
+	assert.Equal(t, []interface{}{1}, args)
+}
+
+func TestInsertFromSelectToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args := s.InsertInto("a").Columns("b", "c").
+		FromSelect(s.Select("x", "y").From("z").Where("x > ?", 3)).
+		OnDuplicateKeyUpdate("c").ToSql()
+	assert.Equal(t, "INSERT INTO a (`b`,`c`) SELECT x, y FROM z WHERE (x > ?) ON DUPLICATE KEY UPDATE `c`=VALUES(`c`)", sql)
+	assert.Equal(t, []interface{}{3}, args)
+
+	sql, args = s.InsertInto("a").Ignore().FromSelect(s.Select("*").From("z")).ToSql()
+	assert.Equal(t, "INSERT IGNORE INTO a SELECT * FROM z", sql)
+	assert.Nil(t, args)
+}
+
+func TestInsertChunks(t *testing.T) {
+	s := createFakeSession()
+
+	b := s.InsertInto("a").Columns("b", "c").Values(1, "xx").Values(2, "yy")
+	chunks, err := b.Chunks()
+	assert.NoError(t, err)
+	assert.Nil(t, chunks) // fits into MaxAllowedPacket
+
+	// INSERT INTO a (`b`,`c`) VALUES  = 31 bytes, each row (1,'xx') = 8 bytes + comma
+	b = s.InsertInto("a").Columns("b", "c").MaxPacket(50)
+	for i := 0; i < 5; i++ {
+		b.Values(i, "xx")
+	}
+	chunks, err = b.Chunks()
+	assert.NoError(t, err)
+	assert.Exactly(t, [][][]interface{}{
+		{{0, "xx"}, {1, "xx"}},
+		{{2, "xx"}, {3, "xx"}},
+		{{4, "xx"}},
+	}, chunks)
+
+	// the placeholder limit applies too
+	b = s.InsertInto("a").Columns("b")
+	for i := 0; i < maxPlaceholders+1; i++ {
+		b.Values(1)
+	}
+	chunks, err = b.Chunks()
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	assert.Len(t, chunks[1], 1)
+
+	b = s.InsertInto("a").Columns("b").Values([]int{}).Values(1).MaxPacket(1)
+	chunks, err = b.Chunks()
+	assert.EqualError(t, err, ErrInvalidSliceLength.Error())
+	assert.Nil(t, chunks)
+
+	// the columns of records without Columns() are derived before chunking
+	b = s.InsertInto("a").MaxPacket(80)
+	for i := 0; i < 5; i++ {
+		b.Record(someRecord{i, 88, false})
+	}
+	chunks, err = b.Chunks()
+	assert.NoError(t, err)
+	assert.Exactly(t, []string{"something_id", "user_id", "other"}, b.Cols)
+	assert.Exactly(t, [][][]interface{}{
+		{{0, int64(88), false}, {1, int64(88), false}},
+		{{2, int64(88), false}, {3, int64(88), false}},
+		{{4, int64(88), false}},
+	}, chunks)
+
+	chunks, err = s.InsertInto("a").Values(1).Values(2).Chunks()
+	assert.EqualError(t, err, ErrNoColumns.Error())
+	assert.Nil(t, chunks)
+}
+
+func TestInsertOnDuplicateKeyReal(t *testing.T) {
+	s := createRealSessionWithFixtures()
+	res, err := s.InsertInto("dbr_people").Columns("id", "name", "email").
+		Values(1, "Jonathan", "jonathan@corestore.io").
+		OnDuplicateKeyUpdate("email").Exec()
+	assert.NoError(t, err)
+	rowsAff, err := res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), rowsAff) // MySQL reports 2 for an updated row
+
+	email, err := s.Select("email").From("dbr_people").Where("id = ?", 1).ReturnString()
+	assert.NoError(t, err)
+	assert.Equal(t, "jonathan@corestore.io", email)
+}
+
+func TestInsertChunksReal(t *testing.T) {
+	s := createRealSessionWithFixtures()
+	b := s.InsertInto("dbr_people").Columns("name", "email").MaxPacket(100)
+	for i := 0; i < 10; i++ {
+		b.Values("Barack", "obama@whitehouse.gov")
+	}
+	res, err := b.Exec()
+	assert.NoError(t, err)
+	rowsAff, err := res.RowsAffected()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), rowsAff)
+
+	count, err := s.Select("COUNT(*)").From("dbr_people").ReturnInt64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), count)
+}
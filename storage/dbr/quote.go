@@ -22,6 +22,29 @@ func (q MysqlQuoter) writeQuotedColumn(column string, sql *bytes.Buffer) {
 	sql.WriteString(Quote + column + Quote)
 }
 
+// writeQuotedIdentifier quotes each part of a dotted identifier like
+// table.column. Identifiers containing parentheses, spaces or quotes are
+// considered as expressions and will be written unquoted.
+func (q MysqlQuoter) writeQuotedIdentifier(ident string, sql *bytes.Buffer) {
+	if strings.ContainsAny(ident, "()` *'\"") {
+		sql.WriteString(ident)
+		return
+	}
+	for i, part := range strings.Split(ident, ".") {
+		if i > 0 {
+			sql.WriteRune('.')
+		}
+		q.writeQuotedColumn(part, sql)
+	}
+}
+
+// quoteIdentifier returns the quoted identifier, see writeQuotedIdentifier.
+func quoteIdentifier(ident string) string {
+	var buf bytes.Buffer
+	Quoter.writeQuotedIdentifier(ident, &buf)
+	return buf.String()
+}
+
 func quoteAs(parts ...string) string {
 	if len(parts) == 1 {
 		return parts[0]
@@ -0,0 +1,128 @@
+package dbr
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// replica a read only database with its own statement cache.
+type replica struct {
+	db      *sql.DB
+	stmts   *StmtCache
+	healthy int32 // 1 if healthy, updated by CheckReplicas()
+}
+
+// AddReplicas adds read replicas to the connection. SELECT statements of a
+// Session outside of a transaction will be routed round-robin to the healthy
+// replicas. Writes and all statements within a transaction use the primary Db.
+// Without a healthy replica the primary will be used. Replicas must not be
+// added while the connection is in use.
+func (cxn *Connection) AddReplicas(dbs ...*sql.DB) *Connection {
+	for _, db := range dbs {
+		r := &replica{db: db, healthy: 1}
+		if cxn.stmts != nil {
+			r.stmts = NewStmtCache(db, cxn.stmts.size)
+		}
+		cxn.replicas = append(cxn.replicas, r)
+	}
+	return cxn
+}
+
+// Replicas returns all replica databases, healthy or not.
+func (cxn *Connection) Replicas() []*sql.DB {
+	dbs := make([]*sql.DB, len(cxn.replicas))
+	for i, r := range cxn.replicas {
+		dbs[i] = r.db
+	}
+	return dbs
+}
+
+// reader returns the next healthy replica or the primary.
+func (cxn *Connection) reader() *sql.DB {
+	n := uint32(len(cxn.replicas))
+	if n == 0 {
+		return cxn.Db
+	}
+	start := atomic.AddUint32(&cxn.next, 1)
+	for i := uint32(0); i < n; i++ {
+		if r := cxn.replicas[(start+i)%n]; atomic.LoadInt32(&r.healthy) == 1 {
+			return r.db
+		}
+	}
+	return cxn.Db
+}
+
+// stmtCache returns the statement cache of the database of the runner.
+func (cxn *Connection) stmtCache(r runner) *StmtCache {
+	if db, ok := r.(*sql.DB); ok && db != cxn.Db {
+		for _, rep := range cxn.replicas {
+			if rep.db == db {
+				return rep.stmts
+			}
+		}
+	}
+	return cxn.stmts
+}
+
+// CheckReplicas pings all replicas. Failing replicas will not receive queries
+// until a later check succeeds. Sends the events dbr.replica.down and
+// dbr.replica.up on state changes. Returns the number of healthy replicas.
+func (cxn *Connection) CheckReplicas(ctx context.Context) int {
+	healthy := 0
+	for i, r := range cxn.replicas {
+		if err := r.db.PingContext(ctx); err != nil {
+			if atomic.SwapInt32(&r.healthy, 0) == 1 {
+				cxn.EventKv("dbr.replica.down", kvs{"replica": strconv.Itoa(i), "err": err.Error()})
+				if r.stmts != nil {
+					r.stmts.Purge()
+				}
+			}
+			continue
+		}
+		healthy++
+		if atomic.SwapInt32(&r.healthy, 1) == 0 {
+			cxn.EventKv("dbr.replica.up", kvs{"replica": strconv.Itoa(i)})
+		}
+	}
+	return healthy
+}
+
+// StartReplicaHealthCheck runs CheckReplicas() every interval in a goroutine
+// until the returned function gets called.
+func (cxn *Connection) StartReplicaHealthCheck(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				cxn.CheckReplicas(ctx)
+				cancel()
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// UsePrimary routes all statements of the session to the primary database,
+// e.g. to read your own writes. Returns a copy of the session.
+func (sess *Session) UsePrimary() *Session {
+	s := *sess
+	s.primary = true
+	return &s
+}
+
+// reader returns the runner for SELECT statements.
+func (sess *Session) reader() runner {
+	if sess.primary {
+		return sess.cxn.Db
+	}
+	return sess.cxn.reader()
+}
@@ -0,0 +1,69 @@
+package dbr
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	open := func(dsn string) *sql.DB {
+		db, err := sql.Open("dbr_stmt_test", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+	primary, r1, r2 := open("primary"), open("r1"), open("down")
+	r := &stmtTestReceiver{events: make(map[string]int)}
+	cxn := NewConnection(primary, r).AddReplicas(r1, r2)
+	s := cxn.NewSession(nil)
+
+	used := map[runner]int{}
+	for i := 0; i < 4; i++ {
+		used[s.Select("n").From("t").runner]++
+	}
+	assert.Exactly(t, 2, used[r1])
+	assert.Exactly(t, 2, used[r2])
+
+	assert.Exactly(t, runner(primary), s.Update("t").runner)
+	assert.Exactly(t, runner(primary), s.UsePrimary().Select("n").runner)
+	assert.False(t, s.primary)
+
+	tx, err := s.Begin()
+	assert.NoError(t, err)
+	assert.Exactly(t, runner(tx.Tx), tx.Select("n").runner)
+	assert.NoError(t, tx.Rollback())
+
+	assert.Exactly(t, 1, cxn.CheckReplicas(context.Background()))
+	assert.Exactly(t, 1, r.events["dbr.replica.down"])
+	for i := 0; i < 4; i++ {
+		assert.Exactly(t, runner(r1), s.Select("n").From("t").runner)
+	}
+
+	// no healthy replica left
+	cxn.replicas[0].healthy = 0
+	assert.Exactly(t, runner(primary), s.Select("n").runner)
+}
+
+func TestReplicaStmtCache(t *testing.T) {
+	primary, _ := sql.Open("dbr_stmt_test", "primary")
+	replica, _ := sql.Open("dbr_stmt_test", "replica")
+	cxn := NewConnection(primary, nil).AddReplicas(replica).EnableStmtCache(10)
+	s := cxn.NewSession(nil)
+
+	n, err := s.Select("n").From("t").Where("id = ?", 1).ReturnInt64()
+	assert.NoError(t, err)
+	assert.Exactly(t, int64(1), n)
+	assert.Exactly(t, 1, cxn.replicas[0].stmts.Len())
+	assert.Exactly(t, 0, cxn.stmts.Len())
+
+	_, err = s.UsePrimary().Select("n").From("t").Where("id = ?", 1).ReturnInt64()
+	assert.NoError(t, err)
+	assert.Exactly(t, 1, cxn.stmts.Len())
+
+	cxn.DisableStmtCache()
+	assert.Nil(t, cxn.replicas[0].stmts)
+}
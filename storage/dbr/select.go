@@ -2,7 +2,6 @@ package dbr
 
 import (
 	"bytes"
-	"fmt"
 )
 
 // SelectBuilder contains the clauses for a SELECT statement
@@ -14,8 +13,16 @@ type SelectBuilder struct {
 	RawArguments []interface{}
 
 	IsDistinct      bool
+	IsStraightJoin  bool
+	IsCalcFoundRows bool
 	Columns         []string
-	FromTable       string
+	// columnSubs contains the scalar subqueries of Columns. Key is the
+	// index in Columns.
+	columnSubs map[int]aliasedQuery
+	FromTable  string
+	// fromSub is the derived table of FROM (SELECT ...) AS alias
+	fromSub         *aliasedQuery
+	FromIndexHints  []IndexHint
 	WhereFragments  []*whereFragment
 	JoinFragments   []*joinFragment
 	GroupBys        []string
@@ -25,13 +32,18 @@ type SelectBuilder struct {
 	LimitValid      bool
 	OffsetCount     uint64
 	OffsetValid     bool
+	// lockMode and lockOption of a locking read, see ForUpdate.
+	lockMode   int
+	lockOption string
 }
 
-// Select creates a new SelectBuilder that select that given columns
+// Select creates a new SelectBuilder that select that given columns. The
+// statement runs on a replica if the connection has replicas, see
+// Connection.AddReplicas and Session.UsePrimary.
 func (sess *Session) Select(cols ...string) *SelectBuilder {
 	return &SelectBuilder{
 		Session: sess,
-		runner:  sess.cxn.Db,
+		runner:  sess.reader(),
 		Columns: cols,
 	}
 }
@@ -40,7 +52,7 @@ func (sess *Session) Select(cols ...string) *SelectBuilder {
 func (sess *Session) SelectBySql(sql string, args ...interface{}) *SelectBuilder {
 	return &SelectBuilder{
 		Session:      sess,
-		runner:       sess.cxn.Db,
+		runner:       sess.reader(),
 		RawFullSql:   sql,
 		RawArguments: args,
 	}
@@ -73,13 +85,50 @@ func (b *SelectBuilder) Distinct() *SelectBuilder {
 
 // From sets the table to SELECT FROM. If second argument will be provided this is
 // then considered as the alias. SELECT ... FROM table AS alias.
-func (b *SelectBuilder) From(from ...string) *SelectBuilder {
-	b.FromTable = quoteAs(from...)
+// The first argument can be a *SelectBuilder or a *UnionBuilder to select from
+// a derived table. Then the alias is required: FROM (SELECT ...) AS `alias`.
+func (b *SelectBuilder) From(from ...interface{}) *SelectBuilder {
+	if len(from) == 0 {
+		panic("no table specified")
+	}
+	if q, ok := from[0].(querier); ok {
+		if len(from) != 2 {
+			panic("a derived table requires an alias")
+		}
+		alias, ok := from[1].(string)
+		if !ok || alias == "" {
+			panic("a derived table requires an alias")
+		}
+		b.FromTable = ""
+		b.fromSub = &aliasedQuery{q: q, alias: alias}
+		return b
+	}
+	parts := make([]string, len(from))
+	for i, f := range from {
+		s, ok := f.(string)
+		if !ok {
+			panic("from can either be a table name or table name and alias")
+		}
+		parts[i] = s
+	}
+	b.fromSub = nil
+	b.FromTable = quoteAs(parts...)
+	return b
+}
+
+// AddColumnSub adds a scalar subquery as a column: (SELECT ...) AS `alias`.
+// The position of the column is the current end of Columns.
+func (b *SelectBuilder) AddColumnSub(sub *SelectBuilder, alias string) *SelectBuilder {
+	if b.columnSubs == nil {
+		b.columnSubs = make(map[int]aliasedQuery)
+	}
+	b.columnSubs[len(b.Columns)] = aliasedQuery{q: sub, alias: alias}
+	b.Columns = append(b.Columns, alias)
 	return b
 }
 
-// Where appends a WHERE clause to the statement for the given string and args
-// or map of column/value pairs
+// Where appends a WHERE clause to the statement for the given string and args,
+// map of column/value pairs or Condition
 func (b *SelectBuilder) Where(whereSqlOrMap interface{}, args ...interface{}) *SelectBuilder {
 	b.WhereFragments = append(b.WhereFragments, newWhereFragment(whereSqlOrMap, args))
 	return b
@@ -91,7 +140,8 @@ func (b *SelectBuilder) GroupBy(group string) *SelectBuilder {
 	return b
 }
 
-// Having appends a HAVING clause to the statement
+// Having appends a HAVING clause to the statement. See Where() for the
+// supported arguments.
 func (b *SelectBuilder) Having(whereSqlOrMap interface{}, args ...interface{}) *SelectBuilder {
 	b.HavingFragments = append(b.HavingFragments, newWhereFragment(whereSqlOrMap, args))
 	return b
@@ -158,7 +208,7 @@ func (b *SelectBuilder) ToSql() (string, []interface{}) {
 	if len(b.Columns) == 0 {
 		panic("no columns specified")
 	}
-	if len(b.FromTable) == 0 {
+	if len(b.FromTable) == 0 && b.fromSub == nil {
 		panic("no table specified")
 	}
 
@@ -170,20 +220,37 @@ func (b *SelectBuilder) ToSql() (string, []interface{}) {
 	if b.IsDistinct {
 		sql.WriteString("DISTINCT ")
 	}
+	if b.IsStraightJoin {
+		sql.WriteString("STRAIGHT_JOIN ")
+	}
+	if b.IsCalcFoundRows {
+		sql.WriteString("SQL_CALC_FOUND_ROWS ")
+	}
 
 	for i, s := range b.Columns {
 		if i > 0 {
 			sql.WriteString(", ")
 		}
+		if sub, ok := b.columnSubs[i]; ok {
+			sub.writeTo(&sql, &args)
+			continue
+		}
 		sql.WriteString(s)
 	}
 
 	sql.WriteString(" FROM ")
-	sql.WriteString(b.FromTable)
+	if b.fromSub != nil {
+		b.fromSub.writeTo(&sql, &args)
+	} else {
+		sql.WriteString(b.FromTable)
+	}
+	writeIndexHints(b.FromIndexHints, &sql)
 
 	if len(b.JoinFragments) > 0 {
 		for _, f := range b.JoinFragments {
-			sql.WriteString(" " + f.joinType + " JOIN " + f.table + " ON ")
+			sql.WriteString(" " + f.joinType + " JOIN " + f.table)
+			writeIndexHints(f.indexHints, &sql)
+			sql.WriteString(" ON ")
 			var w []*whereFragment
 			for _, oc := range f.onConditions {
 				w = append(w, newWhereFragment(oc.whereSqlOrMap, oc.args))
@@ -222,15 +289,9 @@ func (b *SelectBuilder) ToSql() (string, []interface{}) {
 		}
 	}
 
-	if b.LimitValid {
-		sql.WriteString(" LIMIT ")
-		fmt.Fprint(&sql, b.LimitCount)
-	}
+	sql.WriteString(b.dialect().LimitOffset(b.LimitCount, b.OffsetCount, b.LimitValid, b.OffsetValid))
 
-	if b.OffsetValid {
-		sql.WriteString(" OFFSET ")
-		fmt.Fprint(&sql, b.OffsetCount)
-	}
+	b.writeLock(&sql)
 
 	return sql.String(), args
 }
@@ -0,0 +1,120 @@
+package dbr
+
+import "context"
+
+// countAlias alias of the derived table of a wrapped count query
+const countAlias = "count_query"
+
+// CountBuilder derives a SELECT COUNT(*) statement from the SelectBuilder.
+// ORDER BY, LIMIT and OFFSET will be dropped. Queries with GROUP BY, HAVING,
+// DISTINCT or a raw SQL string get wrapped as a derived table:
+//
+//	SELECT COUNT(*) FROM (SELECT ...) AS `count_query`
+//
+// The SelectBuilder itself will not be modified.
+func (b *SelectBuilder) CountBuilder() *SelectBuilder {
+	cb := &SelectBuilder{
+		Session: b.Session,
+		runner:  b.runner,
+		Columns: []string{"COUNT(*)"},
+	}
+
+	if b.RawFullSql != "" {
+		return cb.From(&SelectBuilder{RawFullSql: b.RawFullSql, RawArguments: b.RawArguments}, countAlias)
+	}
+
+	inner := *b
+	inner.Columns = append([]string(nil), b.Columns...)
+	inner.JoinFragments = make([]*joinFragment, len(b.JoinFragments))
+	for i, f := range b.JoinFragments {
+		jf := *f
+		inner.JoinFragments[i] = &jf
+	}
+	inner.OrderBys = nil
+	inner.LimitValid, inner.LimitCount = false, 0
+	inner.OffsetValid, inner.OffsetCount = false, 0
+	inner.IsCalcFoundRows = false
+	inner.lockMode, inner.lockOption = lockNone, ""
+
+	if inner.IsDistinct || len(inner.GroupBys) > 0 || len(inner.HavingFragments) > 0 {
+		return cb.From(&inner, countAlias)
+	}
+
+	// the columns of the joins are not needed for counting
+	for _, f := range inner.JoinFragments {
+		f.columnsAdded = true
+	}
+	inner.Columns = cb.Columns
+	inner.columnSubs = nil
+	return &inner
+}
+
+// Count executes the statement of CountBuilder() and returns the number of
+// rows which the SelectBuilder would return without LIMIT and OFFSET.
+func (b *SelectBuilder) Count() (int64, error) {
+	return b.CountContext(context.Background())
+}
+
+// CountContext same as Count but the query gets cancelled when the context
+// is done.
+func (b *SelectBuilder) CountContext(ctx context.Context) (int64, error) {
+	var c int64
+	err := b.CountBuilder().LoadValueContext(ctx, &c)
+	return c, err
+}
+
+// SeekKey is a sort key for keyset pagination, see Seek().
+type SeekKey struct {
+	Column string
+	Desc   bool
+	// Last value of the column of the last row of the previous page. nil for
+	// the first page.
+	Last interface{}
+}
+
+// Seek applies keyset pagination, also known as seek method, instead of
+// Paginate(). Rows get sorted by the keys and only rows after the last seen
+// values are returned. Contrary to OFFSET the database does not need to read
+// the skipped rows which makes deep pages as fast as the first one. The last
+// key should be unique, e.g. the primary key, to get a stable order. Seek
+// replaces ORDER BY and LIMIT. If Last of the first key is nil the first page
+// will be returned.
+//
+//	sb.Seek(20, SeekKey{Column: "price", Last: 9.99}, SeekKey{Column: "entity_id", Last: 4711})
+//
+// creates
+//
+//	WHERE ((`price` > ?) OR ((`price` = ?) AND (`entity_id` > ?))) ORDER BY `price` ASC, `entity_id` ASC LIMIT 20
+func (b *SelectBuilder) Seek(limit uint64, keys ...SeekKey) *SelectBuilder {
+	if len(keys) == 0 {
+		panic("no seek keys specified")
+	}
+	b.OrderBys = nil
+	for _, k := range keys {
+		b.OrderDir(quoteIdentifier(k.Column), !k.Desc)
+	}
+	b.Limit(limit)
+
+	if keys[0].Last == nil {
+		return b
+	}
+	// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR (k1 = v1 AND k2 = v2 AND k3 > v3) ...
+	ors := make([]Condition, len(keys))
+	for i, k := range keys {
+		ands := make([]Condition, 0, i+1)
+		for _, prev := range keys[:i] {
+			ands = append(ands, Equal(prev.Column, prev.Last))
+		}
+		if k.Desc {
+			ands = append(ands, Less(k.Column, k.Last))
+		} else {
+			ands = append(ands, Greater(k.Column, k.Last))
+		}
+		if len(ands) == 1 {
+			ors[i] = ands[0]
+		} else {
+			ors[i] = And(ands...)
+		}
+	}
+	return b.Where(Or(ors...))
+}
@@ -0,0 +1,71 @@
+package dbr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectCountBuilderToSql(t *testing.T) {
+	s := createFakeSession()
+
+	b := s.Select("e.entity_id", "e.sku").From("catalog_product_entity", "e").
+		Join(JoinTable("catalog_product_website", "w"), []string{"w.website_id"}, JoinOn("w.product_id = e.entity_id")).
+		Where("e.type_id = ?", "simple").
+		OrderBy("e.sku").Paginate(3, 20)
+
+	sql, args := b.CountBuilder().ToSql()
+	assert.Equal(t, "SELECT COUNT(*) FROM `catalog_product_entity` AS `e` INNER JOIN `catalog_product_website` AS `w` ON (w.product_id = e.entity_id) WHERE (e.type_id = ?)", sql)
+	assert.Equal(t, []interface{}{"simple"}, args)
+
+	// original builder is untouched
+	sql, args = b.ToSql()
+	assert.Equal(t, "SELECT e.entity_id, e.sku, w.website_id FROM `catalog_product_entity` AS `e` INNER JOIN `catalog_product_website` AS `w` ON (w.product_id = e.entity_id) WHERE (e.type_id = ?) ORDER BY e.sku LIMIT 20 OFFSET 40", sql)
+	assert.Equal(t, []interface{}{"simple"}, args)
+
+	sql, args = s.Select("a", "COUNT(*)").From("b").Where("c = ?", 1).GroupBy("a").Having("COUNT(*) > ?", 2).OrderBy("a").Limit(5).CountBuilder().ToSql()
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT a, COUNT(*) FROM b WHERE (c = ?) GROUP BY a HAVING (COUNT(*) > ?)) AS `count_query`", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	sql, _ = s.Select("a").Distinct().From("b").CountBuilder().ToSql()
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT DISTINCT a FROM b) AS `count_query`", sql)
+
+	sql, args = s.SelectBySql("SELECT a FROM b WHERE c = ? LIMIT 1", 3).CountBuilder().ToSql()
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT a FROM b WHERE c = ? LIMIT 1) AS `count_query`", sql)
+	assert.Equal(t, []interface{}{3}, args)
+}
+
+func TestSelectSeekToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args := s.Select("a").From("b").Where("c = ?", 1).OrderBy("x").
+		Seek(20, SeekKey{Column: "price"}, SeekKey{Column: "entity_id"}).ToSql()
+	assert.Equal(t, "SELECT a FROM b WHERE (c = ?) ORDER BY `price` ASC, `entity_id` ASC LIMIT 20", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, args = s.Select("a").From("b").
+		Seek(20, SeekKey{Column: "price", Last: 9.99}, SeekKey{Column: "e.entity_id", Last: 4711}).ToSql()
+	assert.Equal(t, "SELECT a FROM b WHERE ((`price` > ?) OR ((`price` = ?) AND (`e`.`entity_id` > ?))) ORDER BY `price` ASC, `e`.`entity_id` ASC LIMIT 20", sql)
+	assert.Equal(t, []interface{}{9.99, 9.99, 4711}, args)
+
+	sql, args = s.Select("a").From("b").
+		Seek(5, SeekKey{Column: "created_at", Desc: true, Last: "2015-10-01"}, SeekKey{Column: "id", Desc: true, Last: 3}, SeekKey{Column: "x", Last: "y"}).ToSql()
+	assert.Equal(t, "SELECT a FROM b WHERE ((`created_at` < ?) OR ((`created_at` = ?) AND (`id` < ?)) OR ((`created_at` = ?) AND (`id` = ?) AND (`x` > ?))) "+
+		"ORDER BY `created_at` DESC, `id` DESC, `x` ASC LIMIT 5", sql)
+	assert.Equal(t, []interface{}{"2015-10-01", "2015-10-01", 3, "2015-10-01", 3, "y"}, args)
+
+	assert.Panics(t, func() { s.Select("a").From("b").Seek(1) })
+}
+
+func TestSelectCountReal(t *testing.T) {
+	s := createRealSessionWithFixtures()
+	n, err := s.Select("id", "name").From("dbr_people").OrderBy("id").Limit(1).Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	var people []*dbrPerson
+	_, err = s.Select("id", "name").From("dbr_people").Seek(1, SeekKey{Column: "id", Last: 1}).LoadStructs(&people)
+	assert.NoError(t, err)
+	assert.Len(t, people, 1)
+	assert.Equal(t, "Dmitri", people[0].Name)
+}
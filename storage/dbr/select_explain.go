@@ -0,0 +1,228 @@
+package dbr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ExplainTable access of one table in the query plan. Table contains the
+// alias if the table has one.
+type ExplainTable struct {
+	Table        string
+	AccessType   string // ALL, index, range, ref, eq_ref, const, system ...
+	PossibleKeys []string
+	Key          string
+	UsedKeyParts []string
+	RowsExamined int64 // estimated rows per scan
+	RowsProduced int64 // estimated rows per join
+	Filtered     float64
+	UsingIndex   bool // covering index
+	Condition    string
+}
+
+// FullScan returns true if all rows of the table will be read.
+func (t ExplainTable) FullScan() bool {
+	return t.AccessType == "ALL"
+}
+
+// ExplainPlan parsed output of EXPLAIN FORMAT=JSON.
+type ExplainPlan struct {
+	// Tables in the order of the join.
+	Tables []ExplainTable
+	// Filesort is true if the result must be sorted without an index.
+	Filesort bool
+	// TempTable is true if a temporary table will be created, e.g. for
+	// GROUP BY, DISTINCT or UNION.
+	TempTable bool
+	// JSON is the raw plan as returned by MySQL.
+	JSON string
+}
+
+// Table returns the access of a table or alias.
+func (p *ExplainPlan) Table(name string) (ExplainTable, bool) {
+	for _, t := range p.Tables {
+		if t.Table == name {
+			return t, true
+		}
+	}
+	return ExplainTable{}, false
+}
+
+// FullScans returns the tables which will be read completely. If names are
+// provided only those tables or aliases will be considered.
+func (p *ExplainPlan) FullScans(names ...string) []ExplainTable {
+	var ts []ExplainTable
+	for _, t := range p.Tables {
+		if !t.FullScan() {
+			continue
+		}
+		if len(names) == 0 {
+			ts = append(ts, t)
+			continue
+		}
+		for _, n := range names {
+			if t.Table == n {
+				ts = append(ts, t)
+				break
+			}
+		}
+	}
+	return ts
+}
+
+// ParseExplain parses the output of EXPLAIN FORMAT=JSON of MySQL >= 5.6.5.
+// Tables of joins, subqueries, derived tables and unions will be collected
+// in the order of their appearance.
+func ParseExplain(data []byte) (*ExplainPlan, error) {
+	var root interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&root); err != nil {
+		return nil, err
+	}
+	p := &ExplainPlan{JSON: string(data)}
+	p.walk(root)
+	return p, nil
+}
+
+// walk searches recursively for tables and the flags of filesort and
+// temporary tables.
+func (p *ExplainPlan) walk(v interface{}) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			p.walk(e)
+		}
+	case map[string]interface{}:
+		if b, _ := v["using_filesort"].(bool); b {
+			p.Filesort = true
+		}
+		if b, _ := v["using_temporary_table"].(bool); b {
+			p.TempTable = true
+		}
+		if t, ok := v["table"].(map[string]interface{}); ok {
+			if _, ok := t["table_name"]; ok {
+				p.Tables = append(p.Tables, newExplainTable(t))
+			}
+		}
+		for _, e := range sortedKeys(v) {
+			p.walk(v[e])
+		}
+	}
+}
+
+func newExplainTable(t map[string]interface{}) ExplainTable {
+	et := ExplainTable{
+		Table:        explainString(t["table_name"]),
+		AccessType:   explainString(t["access_type"]),
+		PossibleKeys: explainStrings(t["possible_keys"]),
+		Key:          explainString(t["key"]),
+		UsedKeyParts: explainStrings(t["used_key_parts"]),
+		RowsExamined: int64(explainFloat(t["rows_examined_per_scan"])),
+		RowsProduced: int64(explainFloat(t["rows_produced_per_join"])),
+		Filtered:     explainFloat(t["filtered"]),
+		Condition:    explainString(t["attached_condition"]),
+	}
+	if et.RowsExamined == 0 { // MySQL 5.6
+		et.RowsExamined = int64(explainFloat(t["rows"]))
+	}
+	et.UsingIndex, _ = t["using_index"].(bool)
+	return et
+}
+
+// sortedKeys returns the keys of a JSON object. The order of a join is kept
+// by the arrays of nested_loop, the keys of objects are sorted to get a
+// stable result.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func explainString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func explainStrings(v interface{}) []string {
+	a, _ := v.([]interface{})
+	if len(a) == 0 {
+		return nil
+	}
+	s := make([]string, 0, len(a))
+	for _, e := range a {
+		s = append(s, explainString(e))
+	}
+	return s
+}
+
+// explainFloat converts numbers and strings like "100.00" of MySQL 5.7.
+func explainFloat(v interface{}) float64 {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return 0
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// Explain runs EXPLAIN FORMAT=JSON for the statement and returns the parsed
+// plan. Requires MySQL >= 5.6.5.
+func (b *SelectBuilder) Explain() (*ExplainPlan, error) {
+	return b.ExplainContext(context.Background())
+}
+
+// ExplainContext same as Explain but the query gets cancelled when the
+// context is done.
+func (b *SelectBuilder) ExplainContext(ctx context.Context) (*ExplainPlan, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	fullSql, args, _, err := b.interpolate(b.ToSql())
+	if err != nil {
+		return nil, eventErrKv(ctx, b.EventReceiver, "dbr.select.explain.interpolate", err, kvs{"sql": fullSql})
+	}
+	fullSql = "EXPLAIN FORMAT=JSON " + fullSql
+
+	startTime := time.Now()
+	defer func() {
+		timingKv(ctx, b.EventReceiver, "dbr.select.explain", time.Since(startTime).Nanoseconds(), timingKvs(fullSql, args))
+	}()
+
+	rows, _, err := b.queryContext(ctx, b.runner, fullSql, args, false)
+	if err != nil {
+		return nil, eventErrKv(ctx, b.EventReceiver, "dbr.select.explain.query", err, kvs{"sql": fullSql})
+	}
+	defer rows.Close()
+
+	var data []byte
+	if rows.Next() {
+		if err := rows.Scan(&data); err != nil {
+			return nil, eventErrKv(ctx, b.EventReceiver, "dbr.select.explain.scan", err, kvs{"sql": fullSql})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, eventErrKv(ctx, b.EventReceiver, "dbr.select.explain.rows_err", err, kvs{"sql": fullSql})
+	}
+	if len(data) == 0 {
+		return nil, eventErrKv(ctx, b.EventReceiver, "dbr.select.explain.empty", ErrNotFound, kvs{"sql": fullSql})
+	}
+
+	p, err := ParseExplain(data)
+	if err != nil {
+		return nil, eventErrKv(ctx, b.EventReceiver, "dbr.select.explain.parse", err, kvs{"sql": fullSql})
+	}
+	return p, nil
+}
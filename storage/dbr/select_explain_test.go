@@ -0,0 +1,90 @@
+package dbr
+
+import (
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// explainTestJSON output of MySQL 5.7 for a join of eav_attribute with a
+// full table scan, a filesort and a temporary table.
+const explainTestJSON = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "129.60"},
+    "ordering_operation": {
+      "using_temporary_table": true,
+      "using_filesort": true,
+      "nested_loop": [
+        {
+          "table": {
+            "table_name": "main_table",
+            "access_type": "ALL",
+            "possible_keys": ["PRIMARY"],
+            "rows_examined_per_scan": 96,
+            "rows_produced_per_join": 9,
+            "filtered": "10.00",
+            "attached_condition": "(main_table.entity_type_id = 4)"
+          }
+        },
+        {
+          "table": {
+            "table_name": "additional_table",
+            "access_type": "eq_ref",
+            "possible_keys": ["PRIMARY"],
+            "key": "PRIMARY",
+            "used_key_parts": ["attribute_id"],
+            "key_length": "2",
+            "rows_examined_per_scan": 1,
+            "rows_produced_per_join": 9,
+            "filtered": "100.00",
+            "using_index": false
+          }
+        }
+      ]
+    }
+  }
+}`
+
+func TestParseExplain(t *testing.T) {
+	p, err := ParseExplain([]byte(explainTestJSON))
+	assert.NoError(t, err)
+	assert.True(t, p.Filesort)
+	assert.True(t, p.TempTable)
+	if assert.Len(t, p.Tables, 2) {
+		assert.Exactly(t, ExplainTable{
+			Table:        "main_table",
+			AccessType:   "ALL",
+			PossibleKeys: []string{"PRIMARY"},
+			RowsExamined: 96,
+			RowsProduced: 9,
+			Filtered:     10,
+			Condition:    "(main_table.entity_type_id = 4)",
+		}, p.Tables[0])
+		assert.Exactly(t, "PRIMARY", p.Tables[1].Key)
+		assert.Exactly(t, []string{"attribute_id"}, p.Tables[1].UsedKeyParts)
+	}
+	assert.Len(t, p.FullScans(), 1)
+	assert.Len(t, p.FullScans("additional_table"), 0)
+	at, ok := p.Table("additional_table")
+	assert.True(t, ok)
+	assert.False(t, at.FullScan())
+
+	_, err = ParseExplain([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestSelectExplain(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	mock.ExpectQuery("EXPLAIN FORMAT=JSON SELECT a FROM `eav_attribute` AS `main_table` WHERE (entity_type_id = 4)").
+		WillReturnRows(csdbtest.NewRows("EXPLAIN").AddRow(explainTestJSON))
+
+	p, err := NewConnection(db, nil).NewSession(nil).
+		Select("a").From("eav_attribute", "main_table").Where("entity_type_id = ?", 4).Explain()
+	if assert.NoError(t, err) {
+		assert.Len(t, p.Tables, 2)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
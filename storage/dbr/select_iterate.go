@@ -0,0 +1,186 @@
+package dbr
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Iterator streams the rows of a SELECT statement one by one into a struct
+// instead of loading the whole result set into memory. An Iterator must be
+// closed. The timing event dbr.select.iterate will be sent once when the
+// Iterator gets closed.
+//
+//	it, err := sess.Select("*").From("catalog_product_entity").Rows()
+//	if err != nil { ... }
+//	defer it.Close()
+//	var p Product
+//	for it.Next() {
+//		if err := it.Scan(&p); err != nil { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type Iterator struct {
+	b       *SelectBuilder
+	ctx     context.Context
+	cancel  context.CancelFunc
+	rows    *sql.Rows
+	release func()
+	fullSql string
+	args    []interface{}
+	start   time.Time
+	columns []string
+	count   int
+	err     error
+	closed  bool
+
+	// field map of the last scanned struct type
+	recordType reflect.Type
+	fieldMap   [][]int
+	holder     []interface{}
+}
+
+// Rows executes the SelectBuilder and returns an Iterator over the result.
+func (b *SelectBuilder) Rows() (*Iterator, error) {
+	return b.RowsContext(context.Background())
+}
+
+// RowsContext same as Rows but the query gets cancelled when the context is
+// done. The default timeout of the session applies to the whole stream if
+// the context has no deadline.
+func (b *SelectBuilder) RowsContext(ctx context.Context) (*Iterator, error) {
+	fullSql, args, prepared, err := b.interpolate(b.ToSql())
+	if err != nil {
+		return nil, b.EventErr("dbr.select.iterate.interpolate", err)
+	}
+
+	it := &Iterator{b: b, fullSql: fullSql, args: args, start: time.Now()}
+	it.ctx, it.cancel = b.withTimeout(ctx)
+
+	it.rows, it.release, err = b.queryContext(it.ctx, b.runner, fullSql, args, prepared)
+	if err != nil {
+		it.cancel()
+		return nil, eventErrKv(it.ctx, b.EventReceiver, "dbr.select.iterate.query", err, kvs{"sql": fullSql})
+	}
+	if it.columns, err = it.rows.Columns(); err != nil {
+		it.Close()
+		return nil, eventErrKv(it.ctx, b.EventReceiver, "dbr.select.iterate.rows.Columns", err, kvs{"sql": fullSql})
+	}
+	return it, nil
+}
+
+// Next prepares the next row for Scan(). Returns false at the end of the
+// result set or on error, see Err(). The Iterator will be closed
+// automatically when Next returns false.
+func (it *Iterator) Next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	if it.rows.Next() {
+		it.count++
+		return true
+	}
+	if err := it.rows.Err(); err != nil {
+		it.err = eventErrKv(it.ctx, it.b.EventReceiver, "dbr.select.iterate.rows_err", err, kvs{"sql": it.fullSql})
+	}
+	it.Close()
+	return false
+}
+
+// Scan copies the current row into dest which must be a pointer to a struct.
+// The mapping of the columns to the fields will be calculated once per
+// struct type. Fields which are not part of the result set keep their value.
+func (it *Iterator) Scan(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("you need to pass in the address of a struct")
+	}
+	v = v.Elem()
+	if it.recordType != v.Type() {
+		fm, err := it.b.calculateFieldMap(v.Type(), it.columns, false)
+		if err != nil {
+			it.err = eventErrKv(it.ctx, it.b.EventReceiver, "dbr.select.iterate.calculateFieldMap", err, kvs{"sql": it.fullSql})
+			return it.err
+		}
+		it.recordType, it.fieldMap, it.holder = v.Type(), fm, make([]interface{}, len(fm))
+	}
+	scannable, err := it.b.prepareHolderFor(v, it.fieldMap, it.holder)
+	if err != nil {
+		return eventErrKv(it.ctx, it.b.EventReceiver, "dbr.select.iterate.holderFor", err, kvs{"sql": it.fullSql})
+	}
+	if err := it.rows.Scan(scannable...); err != nil {
+		return eventErrKv(it.ctx, it.b.EventReceiver, "dbr.select.iterate.scan", err, kvs{"sql": it.fullSql})
+	}
+	return nil
+}
+
+// Columns returns the column names of the result set.
+func (it *Iterator) Columns() []string {
+	return it.columns
+}
+
+// Count returns the number of rows read so far.
+func (it *Iterator) Count() int {
+	return it.count
+}
+
+// Err returns the error which occurred during the iteration.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close closes the result set and sends the timing event. Close can be
+// called several times and allows to stop the iteration early.
+func (it *Iterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	err := it.rows.Close()
+	it.release()
+	kv := timingKvs(it.fullSql, it.args)
+	kv["rows"] = strconv.Itoa(it.count)
+	timingKv(it.ctx, it.b.EventReceiver, "dbr.select.iterate", time.Since(it.start).Nanoseconds(), kv)
+	it.cancel()
+	return err
+}
+
+// Iterate executes the SelectBuilder and scans each row into dest which must
+// be a pointer to a struct. The struct gets reset to its zero value before
+// each row and will be reused, so fn must copy the data it wants to keep.
+// Returning ErrStop from fn stops the iteration without an error, any other
+// error stops the iteration and gets returned. Returns the number of rows
+// read.
+func (b *SelectBuilder) Iterate(dest interface{}, fn func() error) (int, error) {
+	return b.IterateContext(context.Background(), dest, fn)
+}
+
+// IterateContext same as Iterate but the query gets cancelled when the context
+// is done.
+func (b *SelectBuilder) IterateContext(ctx context.Context, dest interface{}, fn func() error) (int, error) {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("you need to pass in the address of a struct")
+	}
+	zero := reflect.Zero(v.Elem().Type())
+
+	it, err := b.RowsContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		v.Elem().Set(zero)
+		if err := it.Scan(dest); err != nil {
+			return it.Count(), err
+		}
+		if err := fn(); err == ErrStop {
+			return it.Count(), nil
+		} else if err != nil {
+			return it.Count(), err
+		}
+	}
+	return it.Count(), it.Err()
+}
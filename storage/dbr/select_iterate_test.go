@@ -0,0 +1,67 @@
+package dbr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type iterTestRecord struct {
+	N int64
+}
+
+func TestSelectIterateStop(t *testing.T) {
+	s, r := newStmtTestSession(10)
+
+	var rec iterTestRecord
+	calls := 0
+	n, err := s.Select("n").From("t").Where("id = ?", 1).Iterate(&rec, func() error {
+		calls++
+		assert.Exactly(t, int64(1), rec.N)
+		return ErrStop
+	})
+	assert.NoError(t, err)
+	assert.Exactly(t, 1, n)
+	assert.Exactly(t, 1, calls)
+
+	errTest := errors.New("test error")
+	_, err = s.Select("n").From("t").Iterate(&rec, func() error { return errTest })
+	assert.Exactly(t, errTest, err)
+
+	it, err := s.Select("n").From("t").Rows()
+	assert.NoError(t, err)
+	assert.Exactly(t, []string{"n"}, it.Columns())
+	assert.NoError(t, it.Close())
+	assert.NoError(t, it.Close())
+	assert.False(t, it.Next())
+	assert.Exactly(t, 3, r.events["dbr.select.iterate"]) // once per stream
+}
+
+func TestSelectIterate(t *testing.T) {
+	s := createRealSessionWithFixtures()
+
+	var names []string
+	var p dbrPerson
+	count, err := s.Select("id", "name", "email").From("dbr_people").OrderBy("id ASC").Iterate(&p, func() error {
+		assert.True(t, p.Id > 0)
+		names = append(names, p.Name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"Jonathan", "Dmitri"}, names)
+
+	it, err := s.Select("id", "name").From("dbr_people").OrderBy("id ASC").Rows()
+	assert.NoError(t, err)
+	defer it.Close()
+	for it.Next() {
+		var p dbrPerson
+		assert.NoError(t, it.Scan(&p))
+		assert.Equal(t, "Jonathan", p.Name)
+		break
+	}
+	assert.NoError(t, it.Close())
+	assert.NoError(t, it.Err())
+	assert.Equal(t, 1, it.Count())
+}
@@ -16,6 +16,8 @@ type (
 		columnsAdded bool
 		// join on condition
 		onConditions []joinOn // slice is joined via AND
+		// index hints for the joined table
+		indexHints []IndexHint
 	}
 )
 
@@ -24,6 +26,8 @@ func JoinTable(tableAlias ...string) []string {
 	return tableAlias
 }
 
+// JoinOn creates an ON condition for a join. w can be a string with
+// placeholders for the args, an Eq map or a Condition.
 func JoinOn(w interface{}, a ...interface{}) joinOn {
 	return joinOn{
 		whereSqlOrMap: w,
@@ -1,6 +1,7 @@
 package dbr
 
 import (
+	"context"
 	"reflect"
 	"time"
 )
@@ -16,6 +17,13 @@ import (
 // dest must be a pointer to a slice of pointers to structs
 // Returns the number of items found (which is not necessarily the # of items set)
 func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
+	return b.LoadStructsContext(context.Background(), dest)
+}
+
+// LoadStructsContext same as LoadStructs but the query gets cancelled when the context
+// is done. The default timeout of the session applies if the context has no
+// deadline.
+func (b *SelectBuilder) LoadStructsContext(ctx context.Context, dest interface{}) (int, error) {
 	//
 	// Validate the dest, and extract the reflection values we need.
 	//
@@ -50,7 +58,7 @@ func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
 	//
 	// Get full SQL
 	//
-	fullSql, err := Interpolate(b.ToSql())
+	fullSql, args, prepared, err := b.interpolate(b.ToSql())
 	if err != nil {
 		return 0, b.EventErr("dbr.select.load_all.interpolate", err)
 	}
@@ -59,25 +67,30 @@ func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		timingKv(ctx, b.EventReceiver, "dbr.select", time.Since(startTime).Nanoseconds(), timingKvs(fullSql, args))
+	}()
 
 	// Run the query:
-	rows, err := b.runner.Query(fullSql)
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	rows, release, err := b.queryContext(ctx, b.runner, fullSql, args, prepared)
+	defer release()
 	if err != nil {
-		return 0, b.EventErrKv("dbr.select.load_all.query", err, kvs{"sql": fullSql})
+		return 0, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_all.query", err, kvs{"sql": fullSql})
 	}
 	defer rows.Close()
 
 	// Get the columns returned
 	columns, err := rows.Columns()
 	if err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_one.rows.Columns", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_one.rows.Columns", err, kvs{"sql": fullSql})
 	}
 
 	// Create a map of this result set to the struct fields
 	fieldMap, err := b.calculateFieldMap(recordType, columns, false)
 	if err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.calculateFieldMap", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_all.calculateFieldMap", err, kvs{"sql": fullSql})
 	}
 
 	// Build a 'holder', which is an []interface{}. Each value will be the set to address of the field corresponding to our newly made records:
@@ -93,13 +106,13 @@ func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
 		// Prepare the holder for this record
 		scannable, err := b.prepareHolderFor(newRecord, fieldMap, holder)
 		if err != nil {
-			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.holderFor", err, kvs{"sql": fullSql})
+			return numberOfRowsReturned, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_all.holderFor", err, kvs{"sql": fullSql})
 		}
 
 		// Load up our new structure with the row's values
 		err = rows.Scan(scannable...)
 		if err != nil {
-			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.scan", err, kvs{"sql": fullSql})
+			return numberOfRowsReturned, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_all.scan", err, kvs{"sql": fullSql})
 		}
 
 		// Append our new record to the slice:
@@ -111,7 +124,7 @@ func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
 
 	// Check for errors at the end. Supposedly these are error that can happen during iteration.
 	if err = rows.Err(); err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all.rows_err", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_all.rows_err", err, kvs{"sql": fullSql})
 	}
 
 	return numberOfRowsReturned, nil
@@ -121,6 +134,13 @@ func (b *SelectBuilder) LoadStructs(dest interface{}) (int, error) {
 // dest must be a pointer to a struct
 // Returns ErrNotFound if nothing was found
 func (b *SelectBuilder) LoadStruct(dest interface{}) error {
+	return b.LoadStructContext(context.Background(), dest)
+}
+
+// LoadStructContext same as LoadStruct but the query gets cancelled when the context
+// is done. The default timeout of the session applies if the context has no
+// deadline.
+func (b *SelectBuilder) LoadStructContext(ctx context.Context, dest interface{}) error {
 	//
 	// Validate the dest, and extract the reflection values we need.
 	//
@@ -137,32 +157,37 @@ func (b *SelectBuilder) LoadStruct(dest interface{}) error {
 	//
 	// Get full SQL
 	//
-	fullSql, err := Interpolate(b.ToSql())
+	fullSql, args, prepared, err := b.interpolate(b.ToSql())
 	if err != nil {
 		return err
 	}
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		timingKv(ctx, b.EventReceiver, "dbr.select", time.Since(startTime).Nanoseconds(), timingKvs(fullSql, args))
+	}()
 
 	// Run the query:
-	rows, err := b.runner.Query(fullSql)
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	rows, release, err := b.queryContext(ctx, b.runner, fullSql, args, prepared)
+	defer release()
 	if err != nil {
-		return b.EventErrKv("dbr.select.load_one.query", err, kvs{"sql": fullSql})
+		return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_one.query", err, kvs{"sql": fullSql})
 	}
 	defer rows.Close()
 
 	// Get the columns of this result set
 	columns, err := rows.Columns()
 	if err != nil {
-		return b.EventErrKv("dbr.select.load_one.rows.Columns", err, kvs{"sql": fullSql})
+		return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_one.rows.Columns", err, kvs{"sql": fullSql})
 	}
 
 	// Create a map of this result set to the struct columns
 	fieldMap, err := b.calculateFieldMap(recordType, columns, false)
 	if err != nil {
-		return b.EventErrKv("dbr.select.load_one.calculateFieldMap", err, kvs{"sql": fullSql})
+		return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_one.calculateFieldMap", err, kvs{"sql": fullSql})
 	}
 
 	// Build a 'holder', which is an []interface{}. Each value will be the set to address of the field corresponding to our newly made records:
@@ -172,19 +197,19 @@ func (b *SelectBuilder) LoadStruct(dest interface{}) error {
 		// Build a 'holder', which is an []interface{}. Each value will be the address of the field corresponding to our newly made record:
 		scannable, err := b.prepareHolderFor(indirectOfDest, fieldMap, holder)
 		if err != nil {
-			return b.EventErrKv("dbr.select.load_one.holderFor", err, kvs{"sql": fullSql})
+			return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_one.holderFor", err, kvs{"sql": fullSql})
 		}
 
 		// Load up our new structure with the row's values
 		err = rows.Scan(scannable...)
 		if err != nil {
-			return b.EventErrKv("dbr.select.load_one.scan", err, kvs{"sql": fullSql})
+			return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_one.scan", err, kvs{"sql": fullSql})
 		}
 		return nil
 	}
 
 	if err := rows.Err(); err != nil {
-		return b.EventErrKv("dbr.select.load_one.rows_err", err, kvs{"sql": fullSql})
+		return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_one.rows_err", err, kvs{"sql": fullSql})
 	}
 
 	return ErrNotFound
@@ -193,6 +218,13 @@ func (b *SelectBuilder) LoadStruct(dest interface{}) error {
 // LoadValues executes the SelectBuilder and loads the resulting data into a slice of primitive values
 // Returns ErrNotFound if no value was found, and it was therefore not set.
 func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
+	return b.LoadValuesContext(context.Background(), dest)
+}
+
+// LoadValuesContext same as LoadValues but the query gets cancelled when the context
+// is done. The default timeout of the session applies if the context has no
+// deadline.
+func (b *SelectBuilder) LoadValuesContext(ctx context.Context, dest interface{}) (int, error) {
 	// Validate the dest and reflection values we need
 
 	// This must be a pointer to a slice
@@ -221,7 +253,7 @@ func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
 	//
 	// Get full SQL
 	//
-	fullSql, err := Interpolate(b.ToSql())
+	fullSql, args, prepared, err := b.interpolate(b.ToSql())
 	if err != nil {
 		return 0, err
 	}
@@ -230,12 +262,17 @@ func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		timingKv(ctx, b.EventReceiver, "dbr.select", time.Since(startTime).Nanoseconds(), timingKvs(fullSql, args))
+	}()
 
 	// Run the query:
-	rows, err := b.runner.Query(fullSql)
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	rows, release, err := b.queryContext(ctx, b.runner, fullSql, args, prepared)
+	defer release()
 	if err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.query", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_all_values.query", err, kvs{"sql": fullSql})
 	}
 	defer rows.Close()
 
@@ -247,7 +284,7 @@ func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
 
 		err = rows.Scan(pointerToNewValue.Interface())
 		if err != nil {
-			return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.scan", err, kvs{"sql": fullSql})
+			return numberOfRowsReturned, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_all_values.scan", err, kvs{"sql": fullSql})
 		}
 
 		// Append our new value to the slice:
@@ -258,7 +295,7 @@ func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
 	valueOfDest.Set(sliceValue)
 
 	if err := rows.Err(); err != nil {
-		return numberOfRowsReturned, b.EventErrKv("dbr.select.load_all_values.rows_err", err, kvs{"sql": fullSql})
+		return numberOfRowsReturned, eventErrKv(ctx, b.EventReceiver, "dbr.select.load_all_values.rows_err", err, kvs{"sql": fullSql})
 	}
 
 	return numberOfRowsReturned, nil
@@ -267,6 +304,13 @@ func (b *SelectBuilder) LoadValues(dest interface{}) (int, error) {
 // LoadValue executes the SelectBuilder and loads the resulting data into a primitive value
 // Returns ErrNotFound if no value was found, and it was therefore not set.
 func (b *SelectBuilder) LoadValue(dest interface{}) error {
+	return b.LoadValueContext(context.Background(), dest)
+}
+
+// LoadValueContext same as LoadValue but the query gets cancelled when the context
+// is done. The default timeout of the session applies if the context has no
+// deadline.
+func (b *SelectBuilder) LoadValueContext(ctx context.Context, dest interface{}) error {
 	// Validate the dest
 	valueOfDest := reflect.ValueOf(dest)
 	kindOfDest := valueOfDest.Kind()
@@ -278,32 +322,37 @@ func (b *SelectBuilder) LoadValue(dest interface{}) error {
 	//
 	// Get full SQL
 	//
-	fullSql, err := Interpolate(b.ToSql())
+	fullSql, args, prepared, err := b.interpolate(b.ToSql())
 	if err != nil {
 		return err
 	}
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.select", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		timingKv(ctx, b.EventReceiver, "dbr.select", time.Since(startTime).Nanoseconds(), timingKvs(fullSql, args))
+	}()
 
 	// Run the query:
-	rows, err := b.runner.Query(fullSql)
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+	rows, release, err := b.queryContext(ctx, b.runner, fullSql, args, prepared)
+	defer release()
 	if err != nil {
-		return b.EventErrKv("dbr.select.load_value.query", err, kvs{"sql": fullSql})
+		return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_value.query", err, kvs{"sql": fullSql})
 	}
 	defer rows.Close()
 
 	if rows.Next() {
 		err = rows.Scan(dest)
 		if err != nil {
-			return b.EventErrKv("dbr.select.load_value.scan", err, kvs{"sql": fullSql})
+			return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_value.scan", err, kvs{"sql": fullSql})
 		}
 		return nil
 	}
 
 	if err := rows.Err(); err != nil {
-		return b.EventErrKv("dbr.select.load_value.rows_err", err, kvs{"sql": fullSql})
+		return eventErrKv(ctx, b.EventReceiver, "dbr.select.load_value.rows_err", err, kvs{"sql": fullSql})
 	}
 
 	return ErrNotFound
@@ -0,0 +1,150 @@
+package dbr
+
+import (
+	"bytes"
+	"database/sql"
+)
+
+const (
+	lockNone = iota
+	lockUpdate
+	lockShare
+)
+
+// IndexHint is a MySQL index hint for a table, created with UseIndex,
+// ForceIndex or IgnoreIndex.
+type IndexHint struct {
+	// Type is USE, FORCE or IGNORE.
+	Type string
+	// For restricts the hint to JOIN, ORDER BY or GROUP BY. Empty applies to
+	// all.
+	For     string
+	Indexes []string
+}
+
+// UseIndex creates the hint USE INDEX (`idx`, ...)
+func UseIndex(indexes ...string) IndexHint { return IndexHint{Type: "USE", Indexes: indexes} }
+
+// ForceIndex creates the hint FORCE INDEX (`idx`, ...)
+func ForceIndex(indexes ...string) IndexHint { return IndexHint{Type: "FORCE", Indexes: indexes} }
+
+// IgnoreIndex creates the hint IGNORE INDEX (`idx`, ...)
+func IgnoreIndex(indexes ...string) IndexHint { return IndexHint{Type: "IGNORE", Indexes: indexes} }
+
+// ForJoin restricts the hint to finding rows for joins.
+func (h IndexHint) ForJoin() IndexHint { h.For = "JOIN"; return h }
+
+// ForOrderBy restricts the hint to sorting.
+func (h IndexHint) ForOrderBy() IndexHint { h.For = "ORDER BY"; return h }
+
+// ForGroupBy restricts the hint to grouping.
+func (h IndexHint) ForGroupBy() IndexHint { h.For = "GROUP BY"; return h }
+
+func writeIndexHints(hints []IndexHint, sql *bytes.Buffer) {
+	for _, h := range hints {
+		sql.WriteString(" " + h.Type + " INDEX ")
+		if h.For != "" {
+			sql.WriteString("FOR " + h.For + " ")
+		}
+		sql.WriteRune('(')
+		for i, idx := range h.Indexes {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			Quoter.writeQuotedColumn(idx, sql)
+		}
+		sql.WriteRune(')')
+	}
+}
+
+// FromIndexHint adds index hints to the table of FROM.
+//
+//	sess.Select("*").From("sales_order").FromIndexHint(ForceIndex("IDX_SALES_ORDER_STATUS"))
+func (b *SelectBuilder) FromIndexHint(hints ...IndexHint) *SelectBuilder {
+	b.FromIndexHints = append(b.FromIndexHints, hints...)
+	return b
+}
+
+// JoinIndexHint adds index hints to the table of the last join. Panics if
+// there is no join.
+func (b *SelectBuilder) JoinIndexHint(hints ...IndexHint) *SelectBuilder {
+	if len(b.JoinFragments) == 0 {
+		panic("no join specified")
+	}
+	f := b.JoinFragments[len(b.JoinFragments)-1]
+	f.indexHints = append(f.indexHints, hints...)
+	return b
+}
+
+// StraightJoin forces the optimizer to join the tables in the order in which
+// they are listed: SELECT STRAIGHT_JOIN ...
+func (b *SelectBuilder) StraightJoin() *SelectBuilder {
+	b.IsStraightJoin = true
+	return b
+}
+
+// CalcFoundRows adds SQL_CALC_FOUND_ROWS. SELECT FOUND_ROWS() must run on the
+// same connection afterwards, e.g. within a transaction.
+func (b *SelectBuilder) CalcFoundRows() *SelectBuilder {
+	b.IsCalcFoundRows = true
+	return b
+}
+
+// ForUpdate locks the selected rows for writing: FOR UPDATE. A locking read
+// only makes sense within a transaction. The statement will never be routed
+// to a replica.
+func (b *SelectBuilder) ForUpdate() *SelectBuilder {
+	return b.lock(lockUpdate)
+}
+
+// LockInShareMode locks the selected rows for reading: LOCK IN SHARE MODE,
+// or FOR SHARE in combination with NoWait or SkipLocked. The statement will
+// never be routed to a replica.
+func (b *SelectBuilder) LockInShareMode() *SelectBuilder {
+	return b.lock(lockShare)
+}
+
+// NoWait fails immediately instead of waiting if a row is locked. Requires
+// ForUpdate or LockInShareMode and MySQL >= 8.0.
+func (b *SelectBuilder) NoWait() *SelectBuilder {
+	b.lockOption = "NOWAIT"
+	return b
+}
+
+// SkipLocked removes locked rows from the result set instead of waiting.
+// Requires ForUpdate or LockInShareMode and MySQL >= 8.0. Useful for queue
+// like tables, e.g. reserving the next free increment ID.
+func (b *SelectBuilder) SkipLocked() *SelectBuilder {
+	b.lockOption = "SKIP LOCKED"
+	return b
+}
+
+func (b *SelectBuilder) lock(mode int) *SelectBuilder {
+	b.lockMode = mode
+	// locking reads must run on the primary
+	if db, ok := b.runner.(*sql.DB); ok && b.Session != nil && db != b.cxn.Db {
+		b.runner = b.cxn.Db
+	}
+	return b
+}
+
+// writeLock writes the locking clause at the end of the statement.
+func (b *SelectBuilder) writeLock(sql *bytes.Buffer) {
+	switch {
+	case b.lockMode == lockNone:
+		if b.lockOption != "" {
+			panic(b.lockOption + " requires ForUpdate or LockInShareMode")
+		}
+		return
+	case b.lockMode == lockUpdate:
+		sql.WriteString(" FOR UPDATE")
+	case b.lockOption == "":
+		sql.WriteString(" LOCK IN SHARE MODE")
+		return
+	default:
+		sql.WriteString(" FOR SHARE")
+	}
+	if b.lockOption != "" {
+		sql.WriteString(" " + b.lockOption)
+	}
+}
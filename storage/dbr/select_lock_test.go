@@ -0,0 +1,62 @@
+package dbr
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectLockToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args := s.Select("increment_id").From("sequence_order").Where("store_id = ?", 1).Limit(1).ForUpdate().ToSql()
+	assert.Equal(t, "SELECT increment_id FROM sequence_order WHERE (store_id = ?) LIMIT 1 FOR UPDATE", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, _ = s.Select("qty").From("cataloginventory_stock_item").ForUpdate().NoWait().ToSql()
+	assert.Equal(t, "SELECT qty FROM cataloginventory_stock_item FOR UPDATE NOWAIT", sql)
+
+	sql, _ = s.Select("qty").From("cataloginventory_stock_item").ForUpdate().SkipLocked().ToSql()
+	assert.Equal(t, "SELECT qty FROM cataloginventory_stock_item FOR UPDATE SKIP LOCKED", sql)
+
+	sql, _ = s.Select("qty").From("cataloginventory_stock_item").LockInShareMode().ToSql()
+	assert.Equal(t, "SELECT qty FROM cataloginventory_stock_item LOCK IN SHARE MODE", sql)
+
+	sql, _ = s.Select("qty").From("cataloginventory_stock_item").LockInShareMode().SkipLocked().ToSql()
+	assert.Equal(t, "SELECT qty FROM cataloginventory_stock_item FOR SHARE SKIP LOCKED", sql)
+
+	assert.Panics(t, func() { s.Select("a").From("b").NoWait().ToSql() })
+
+	sql, _ = s.Select("a").From("b").ForUpdate().CalcFoundRows().Limit(2).CountBuilder().ToSql()
+	assert.Equal(t, "SELECT COUNT(*) FROM b", sql)
+}
+
+func TestSelectHintsToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args := s.Select("e.entity_id").Distinct().StraightJoin().CalcFoundRows().
+		From("catalog_product_entity", "e").FromIndexHint(ForceIndex("PRIMARY")).
+		Join(JoinTable("catalog_product_website", "w"), nil, JoinOn("w.product_id = e.entity_id")).
+		JoinIndexHint(UseIndex("IDX_WEBSITE_ID", "IDX_PRODUCT_ID").ForJoin(), IgnoreIndex("IDX_X").ForOrderBy()).
+		Where("w.website_id = ?", 1).Limit(10).ToSql()
+	assert.Equal(t, "SELECT DISTINCT STRAIGHT_JOIN SQL_CALC_FOUND_ROWS e.entity_id FROM `catalog_product_entity` AS `e` FORCE INDEX (`PRIMARY`) "+
+		"INNER JOIN `catalog_product_website` AS `w` USE INDEX FOR JOIN (`IDX_WEBSITE_ID`, `IDX_PRODUCT_ID`) IGNORE INDEX FOR ORDER BY (`IDX_X`) "+
+		"ON (w.product_id = e.entity_id) WHERE (w.website_id = ?) LIMIT 10", sql)
+	assert.Equal(t, []interface{}{1}, args)
+
+	sql, _ = s.Select("a").From("b").FromIndexHint(UseIndex().ForGroupBy()).ToSql()
+	assert.Equal(t, "SELECT a FROM b USE INDEX FOR GROUP BY ()", sql)
+
+	assert.Panics(t, func() { s.Select("a").From("b").JoinIndexHint(UseIndex("x")) })
+}
+
+func TestSelectLockUsesPrimary(t *testing.T) {
+	primary, _ := sql.Open("dbr_stmt_test", "primary")
+	replica, _ := sql.Open("dbr_stmt_test", "replica")
+	s := NewConnection(primary, nil).AddReplicas(replica).NewSession(nil)
+
+	assert.Exactly(t, runner(replica), s.Select("a").runner)
+	assert.Exactly(t, runner(primary), s.Select("a").ForUpdate().runner)
+	assert.Exactly(t, runner(primary), s.Select("a").LockInShareMode().runner)
+}
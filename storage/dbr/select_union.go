@@ -0,0 +1,184 @@
+package dbr
+
+import (
+	"bytes"
+	"context"
+)
+
+// querier is implemented by the builders which can be used as subquery.
+type querier interface {
+	ToSql() (string, []interface{})
+}
+
+// aliasedQuery a subquery with an alias: (SELECT ...) AS `alias`
+type aliasedQuery struct {
+	q     querier
+	alias string
+}
+
+func (a aliasedQuery) writeTo(sql *bytes.Buffer, args *[]interface{}) {
+	q, qArgs := a.q.ToSql()
+	sql.WriteRune('(')
+	sql.WriteString(q)
+	sql.WriteString(") AS ")
+	Quoter.writeQuotedColumn(a.alias, sql)
+	*args = append(*args, qArgs...)
+}
+
+// UnionBuilder combines several SELECT statements with UNION or UNION ALL.
+// ORDER BY, LIMIT and OFFSET apply to the combined result. The arguments
+// are collected in the order of the statements.
+type UnionBuilder struct {
+	*Session
+	runner
+
+	Selects     []*SelectBuilder
+	IsAll       bool
+	OrderBys    []string
+	LimitCount  uint64
+	LimitValid  bool
+	OffsetCount uint64
+	OffsetValid bool
+}
+
+// Union creates a new UnionBuilder for the SELECT statements.
+func (sess *Session) Union(selects ...*SelectBuilder) *UnionBuilder {
+	return &UnionBuilder{
+		Session: sess,
+		runner:  sess.reader(),
+		Selects: selects,
+	}
+}
+
+// UnionAll creates a new UnionBuilder which keeps duplicate rows.
+func (sess *Session) UnionAll(selects ...*SelectBuilder) *UnionBuilder {
+	return sess.Union(selects...).All()
+}
+
+// Union creates a new UnionBuilder for the SELECT statements bound to the transaction
+func (tx *Tx) Union(selects ...*SelectBuilder) *UnionBuilder {
+	return &UnionBuilder{
+		Session: tx.Session,
+		runner:  tx.Tx,
+		Selects: selects,
+	}
+}
+
+// UnionAll creates a new UnionBuilder which keeps duplicate rows bound to the transaction
+func (tx *Tx) UnionAll(selects ...*SelectBuilder) *UnionBuilder {
+	return tx.Union(selects...).All()
+}
+
+// Add appends further SELECT statements.
+func (u *UnionBuilder) Add(selects ...*SelectBuilder) *UnionBuilder {
+	u.Selects = append(u.Selects, selects...)
+	return u
+}
+
+// All uses UNION ALL instead of UNION to keep duplicate rows.
+func (u *UnionBuilder) All() *UnionBuilder {
+	u.IsAll = true
+	return u
+}
+
+// OrderBy appends a column to ORDER the combined result by
+func (u *UnionBuilder) OrderBy(ord string) *UnionBuilder {
+	u.OrderBys = append(u.OrderBys, ord)
+	return u
+}
+
+// OrderDir appends a column to ORDER the combined result by with a given direction
+func (u *UnionBuilder) OrderDir(ord string, isAsc bool) *UnionBuilder {
+	if isAsc {
+		u.OrderBys = append(u.OrderBys, ord+" ASC")
+	} else {
+		u.OrderBys = append(u.OrderBys, ord+" DESC")
+	}
+	return u
+}
+
+// Limit sets a limit for the combined result; overrides any existing LIMIT
+func (u *UnionBuilder) Limit(limit uint64) *UnionBuilder {
+	u.LimitCount = limit
+	u.LimitValid = true
+	return u
+}
+
+// Offset sets an offset for the combined result; overrides any existing OFFSET
+func (u *UnionBuilder) Offset(offset uint64) *UnionBuilder {
+	u.OffsetCount = offset
+	u.OffsetValid = true
+	return u
+}
+
+// ToSql serialized the UnionBuilder to a SQL string
+// It returns the string with placeholders and a slice of query arguments
+func (u *UnionBuilder) ToSql() (string, []interface{}) {
+	if len(u.Selects) == 0 {
+		panic("no select statements specified")
+	}
+
+	var sql bytes.Buffer
+	var args []interface{}
+
+	for i, sb := range u.Selects {
+		if i > 0 {
+			if u.IsAll {
+				sql.WriteString(" UNION ALL ")
+			} else {
+				sql.WriteString(" UNION ")
+			}
+		}
+		q, qArgs := sb.ToSql()
+		sql.WriteString(u.dialect().UnionOperand(q))
+		args = append(args, qArgs...)
+	}
+
+	if len(u.OrderBys) > 0 {
+		sql.WriteString(" ORDER BY ")
+		for i, s := range u.OrderBys {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(s)
+		}
+	}
+
+	sql.WriteString(u.dialect().LimitOffset(u.LimitCount, u.OffsetCount, u.LimitValid, u.OffsetValid))
+
+	return sql.String(), args
+}
+
+// selectBuilder wraps the union into a raw SelectBuilder to reuse the load
+// functions.
+func (u *UnionBuilder) selectBuilder() *SelectBuilder {
+	q, args := u.ToSql()
+	return &SelectBuilder{
+		Session:      u.Session,
+		runner:       u.runner,
+		RawFullSql:   q,
+		RawArguments: args,
+	}
+}
+
+// LoadStructs executes the UnionBuilder and loads the resulting data into a
+// slice of structs. See SelectBuilder.LoadStructs.
+func (u *UnionBuilder) LoadStructs(dest interface{}) (int, error) {
+	return u.selectBuilder().LoadStructs(dest)
+}
+
+// LoadStructsContext same as LoadStructs with a context.
+func (u *UnionBuilder) LoadStructsContext(ctx context.Context, dest interface{}) (int, error) {
+	return u.selectBuilder().LoadStructsContext(ctx, dest)
+}
+
+// LoadValues executes the UnionBuilder and loads the resulting data into a
+// slice of primitive values. See SelectBuilder.LoadValues.
+func (u *UnionBuilder) LoadValues(dest interface{}) (int, error) {
+	return u.selectBuilder().LoadValues(dest)
+}
+
+// LoadValuesContext same as LoadValues with a context.
+func (u *UnionBuilder) LoadValuesContext(ctx context.Context, dest interface{}) (int, error) {
+	return u.selectBuilder().LoadValuesContext(ctx, dest)
+}
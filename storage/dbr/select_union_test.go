@@ -0,0 +1,73 @@
+package dbr
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectFromSubToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sub := s.Select("entity_id", "COUNT(*) AS cnt").From("sales_order_item").Where("store_id = ?", 1).GroupBy("entity_id")
+	sql, args := s.Select("t.entity_id").
+		AddColumnSub(s.Select("sku").From("catalog_product_entity", "e").Where("e.entity_id = t.entity_id AND e.type_id = ?", "simple"), "sku").
+		From(sub, "t").
+		Where("t.cnt > ?", 5).ToSql()
+	assert.Equal(t, "SELECT t.entity_id, (SELECT sku FROM `catalog_product_entity` AS `e` WHERE (e.entity_id = t.entity_id AND e.type_id = ?)) AS `sku` "+
+		"FROM (SELECT entity_id, COUNT(*) AS cnt FROM sales_order_item WHERE (store_id = ?) GROUP BY entity_id) AS `t` WHERE (t.cnt > ?)", sql)
+	assert.Equal(t, []interface{}{"simple", 1, 5}, args)
+
+	assert.Panics(t, func() { s.Select("a").From(sub) })
+	assert.Panics(t, func() { s.Select("a").From(1) })
+}
+
+func TestUnionToSql(t *testing.T) {
+	s := createFakeSession()
+
+	var selects []*SelectBuilder
+	for _, typ := range []string{"int", "varchar", "decimal", "text", "datetime"} {
+		selects = append(selects, s.Select("entity_id", "attribute_id", "store_id", "value").
+			From("catalog_product_entity_"+typ).
+			Where(And(In("entity_id", []int64{1, 2}), In("store_id", []int64{0, 1}))),
+		)
+	}
+	u := s.UnionAll(selects[:2]...).Add(selects[2:]...).OrderBy("entity_id").OrderDir("store_id", false).Limit(10).Offset(20)
+	sql, args := u.ToSql()
+
+	const part = "SELECT entity_id, attribute_id, store_id, value FROM catalog_product_entity_%s WHERE ((`entity_id` IN (?,?)) AND (`store_id` IN (?,?)))"
+	want := "(" + fmtPart(part, "int") + ") UNION ALL (" + fmtPart(part, "varchar") + ") UNION ALL (" + fmtPart(part, "decimal") + ") UNION ALL (" +
+		fmtPart(part, "text") + ") UNION ALL (" + fmtPart(part, "datetime") + ") ORDER BY entity_id, store_id DESC LIMIT 10 OFFSET 20"
+	assert.Equal(t, want, sql)
+	assert.Len(t, args, 20)
+	assert.Equal(t, []interface{}{int64(1), int64(2), int64(0), int64(1)}, args[16:])
+
+	sql, args = s.Union(s.Select("a").From("b").Where("c = ?", 1), s.Select("a").From("d").Where("e = ?", 2)).ToSql()
+	assert.Equal(t, "(SELECT a FROM b WHERE (c = ?)) UNION (SELECT a FROM d WHERE (e = ?))", sql)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	// union as derived table and as IN subquery
+	sql, args = s.Select("COUNT(*)").From(s.Union(s.Select("a").From("b").Where("c = ?", 1), s.Select("a").From("d")), "u").
+		Where(NotIn("u.a", s.Union(s.Select("x").From("y").Where("z = ?", 3)))).ToSql()
+	assert.Equal(t, "SELECT COUNT(*) FROM ((SELECT a FROM b WHERE (c = ?)) UNION (SELECT a FROM d)) AS `u` WHERE (`u`.`a` NOT IN ((SELECT x FROM y WHERE (z = ?))))", sql)
+	assert.Equal(t, []interface{}{1, 3}, args)
+
+	assert.Panics(t, func() { s.Union().ToSql() })
+}
+
+func fmtPart(part, typ string) string {
+	return fmt.Sprintf(part, typ)
+}
+
+func TestUnionLoadValuesReal(t *testing.T) {
+	s := createRealSessionWithFixtures()
+	var names []string
+	n, err := s.UnionAll(
+		s.Select("name").From("dbr_people").Where(Equal("id", 1)),
+		s.Select("name").From("dbr_people").Where(Equal("id", 2)),
+	).OrderBy("name").LoadValues(&names)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"Dmitri", "Jonathan"}, names)
+}
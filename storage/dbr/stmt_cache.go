@@ -0,0 +1,279 @@
+package dbr
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"sync"
+)
+
+// DefaultStmtCacheSize default number of prepared statements kept by
+// Connection.EnableStmtCache() if the size is <= 0.
+const DefaultStmtCacheSize = 128
+
+// StmtCache keeps server side prepared statements in a least recently used
+// cache. The key is the SQL with the placeholders before interpolation. A
+// StmtCache is safe for concurrent use. Statements which are still in use
+// when they get evicted will be closed after the last usage.
+type StmtCache struct {
+	db   *sql.DB
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List // front is the most recently used statement
+	items map[string]*list.Element
+}
+
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+// NewStmtCache creates a new statement cache for a database with a maximum
+// of size statements.
+func NewStmtCache(db *sql.DB, size int) *StmtCache {
+	if size <= 0 {
+		size = DefaultStmtCacheSize
+	}
+	return &StmtCache{
+		db:    db,
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Len returns the number of cached statements.
+func (c *StmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// acquire returns the cached statement for a query or prepares a new one.
+// The bool reports a cache hit. release() must be called after the usage.
+func (c *StmtCache) acquire(ctx context.Context, query string) (*cachedStmt, bool, error) {
+	c.mu.Lock()
+	if e, ok := c.items[query]; ok {
+		c.ll.MoveToFront(e)
+		cs := e.Value.(*cachedStmt)
+		cs.refs++
+		c.mu.Unlock()
+		return cs, true, nil
+	}
+	c.mu.Unlock()
+
+	// prepare without holding the lock to not block other queries
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[query]; ok { // another goroutine has been faster
+		stmt.Close()
+		c.ll.MoveToFront(e)
+		cs := e.Value.(*cachedStmt)
+		cs.refs++
+		return cs, false, nil
+	}
+	cs := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.ll.PushFront(cs)
+	for c.ll.Len() > c.size {
+		c.evict(c.ll.Back())
+	}
+	return cs, false, nil
+}
+
+// release decrements the usage counter and closes an evicted statement.
+func (c *StmtCache) release(cs *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cs.refs--
+	if cs.evicted && cs.refs == 0 {
+		cs.stmt.Close()
+	}
+}
+
+// evict removes an element from the cache. Lock must be held.
+func (c *StmtCache) evict(e *list.Element) {
+	cs := c.ll.Remove(e).(*cachedStmt)
+	delete(c.items, cs.query)
+	cs.evicted = true
+	if cs.refs == 0 {
+		cs.stmt.Close()
+	}
+}
+
+// Remove invalidates the prepared statement of a query.
+func (c *StmtCache) Remove(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[query]; ok {
+		c.evict(e)
+	}
+}
+
+// Purge invalidates all prepared statements.
+func (c *StmtCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for e := c.ll.Back(); e != nil; e = c.ll.Back() {
+		c.evict(e)
+	}
+}
+
+// EnableStmtCache enables the cache for prepared statements for all sessions
+// of the connection. Queries with arguments which cannot be sent to the
+// driver, e.g. slices for IN(), will still be interpolated. size <= 0
+// applies DefaultStmtCacheSize.
+func (cxn *Connection) EnableStmtCache(size int) *Connection {
+	cxn.DisableStmtCache()
+	cxn.stmts = NewStmtCache(cxn.Db, size)
+	for _, r := range cxn.replicas {
+		r.stmts = NewStmtCache(r.db, size)
+	}
+	return cxn
+}
+
+// DisableStmtCache closes all cached prepared statements and switches back
+// to interpolated queries.
+func (cxn *Connection) DisableStmtCache() {
+	if cxn.stmts != nil {
+		cxn.stmts.Purge()
+		cxn.stmts = nil
+	}
+	for _, r := range cxn.replicas {
+		if r.stmts != nil {
+			r.stmts.Purge()
+			r.stmts = nil
+		}
+	}
+}
+
+// interpolate returns the SQL and the arguments for the runner. With the
+// statement cache the SQL stays untouched and prepared is true, otherwise the
+// arguments will be interpolated into the SQL. Dialects other than MySQL get
+// the translated SQL and the arguments, see Rebind.
+func (sess *Session) interpolate(query string, args []interface{}) (string, []interface{}, bool, error) {
+	if d := sess.dialect(); d != MySQL {
+		q, a, err := Rebind(d, query, args)
+		return q, a, err == nil && sess.cxn.stmts != nil, err
+	}
+	if sess.cxn.stmts != nil && isDriverArgs(args) {
+		return query, args, true, nil
+	}
+	fullSql, err := Interpolate(query, args)
+	if err != nil {
+		return "", args, false, err
+	}
+	return fullSql, nil, false, nil
+}
+
+// prepare fetches the statement from the cache of the database of the runner
+// and binds it to a transaction if the runner is a *sql.Tx. The returned func
+// releases the statement.
+func (sess *Session) prepare(ctx context.Context, r runner, query string) (*sql.Stmt, func(), error) {
+	c := sess.cxn.stmtCache(r)
+	cs, hit, err := c.acquire(ctx, query)
+	if err != nil {
+		sess.stmtErr(c, query, err)
+		return nil, nil, err
+	}
+	if hit {
+		sess.EventKv("dbr.stmt_cache.hit", kvs{"sql": query})
+	} else {
+		sess.EventKv("dbr.stmt_cache.miss", kvs{"sql": query})
+	}
+	if tx, ok := r.(*sql.Tx); ok {
+		stmt := tx.StmtContext(ctx, cs.stmt)
+		return stmt, func() { stmt.Close(); c.release(cs) }, nil
+	}
+	return cs.stmt, func() { c.release(cs) }, nil
+}
+
+// execContext runs the query as prepared statement or as plain SQL.
+func (sess *Session) execContext(ctx context.Context, r runner, query string, args []interface{}, prepared bool) (sql.Result, error) {
+	if !prepared {
+		return r.ExecContext(ctx, query, args...)
+	}
+	stmt, release, err := sess.prepare(ctx, r, query)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		sess.stmtErr(sess.cxn.stmtCache(r), query, err)
+	}
+	return res, err
+}
+
+// queryContext runs the query as prepared statement or as plain SQL. The
+// returned func must be called after the rows have been closed.
+func (sess *Session) queryContext(ctx context.Context, r runner, query string, args []interface{}, prepared bool) (*sql.Rows, func(), error) {
+	if !prepared {
+		rows, err := r.QueryContext(ctx, query, args...)
+		return rows, func() {}, err
+	}
+	stmt, release, err := sess.prepare(ctx, r, query)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		sess.stmtErr(sess.cxn.stmtCache(r), query, err)
+		release()
+		return nil, func() {}, err
+	}
+	return rows, release, nil
+}
+
+// stmtErr invalidates the statement on connection errors.
+func (sess *Session) stmtErr(c *StmtCache, query string, err error) {
+	if isConnErr(err) {
+		c.Remove(query)
+		sess.EventKv("dbr.stmt_cache.invalidate", kvs{"sql": query, "err": err.Error()})
+	}
+}
+
+func isConnErr(err error) bool {
+	var ne net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &ne)
+}
+
+// isDriverArgs returns true if all arguments can be sent to the driver
+// without interpolation.
+func isDriverArgs(args []interface{}) bool {
+	for _, a := range args {
+		if v, ok := a.(driver.Valuer); ok {
+			var err error
+			if a, err = v.Value(); err != nil {
+				return false
+			}
+		}
+		switch a.(type) {
+		case nil, []byte, string, bool, float32, float64, int, int8, int16, int32, int64,
+			uint, uint8, uint16, uint32:
+			continue
+		case uint64:
+			if a.(uint64) > 1<<63-1 {
+				return false
+			}
+			continue
+		}
+		if reflect.TypeOf(a) != typeOfTime {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+package dbr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stmtTestDriver counts the prepared and closed statements. Queries return
+// one row with the column n and the value 1.
+type stmtTestDriver struct {
+	prepared int32
+	closed   int32
+	badConn  int32 // if > 0 the next executions return driver.ErrBadConn
+
+	mu      sync.Mutex
+	queries []string // all prepared queries
+}
+
+// lastQueries returns and resets the prepared queries.
+func (d *stmtTestDriver) lastQueries() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	q := d.queries
+	d.queries = nil
+	return q
+}
+
+var stmtDriver = &stmtTestDriver{}
+
+func init() {
+	sql.Register("dbr_stmt_test", stmtDriver)
+}
+
+// Open fails for the data source name "down".
+func (d *stmtTestDriver) Open(name string) (driver.Conn, error) {
+	if name == "down" {
+		return nil, errors.New("connection refused")
+	}
+	return &stmtTestConn{d: d}, nil
+}
+
+type stmtTestConn struct{ d *stmtTestDriver }
+
+func (c *stmtTestConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt32(&c.d.prepared, 1)
+	c.d.mu.Lock()
+	c.d.queries = append(c.d.queries, query)
+	c.d.mu.Unlock()
+	return &stmtTestStmt{d: c.d}, nil
+}
+func (c *stmtTestConn) Close() error              { return nil }
+func (c *stmtTestConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *stmtTestConn) Commit() error             { return nil }
+func (c *stmtTestConn) Rollback() error           { return nil }
+
+type stmtTestStmt struct{ d *stmtTestDriver }
+
+func (s *stmtTestStmt) Close() error  { atomic.AddInt32(&s.d.closed, 1); return nil }
+func (s *stmtTestStmt) NumInput() int { return -1 }
+func (s *stmtTestStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if atomic.LoadInt32(&s.d.badConn) > 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s *stmtTestStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &stmtTestRows{}, nil
+}
+
+type stmtTestRows struct{ done bool }
+
+func (r *stmtTestRows) Columns() []string { return []string{"n"} }
+func (r *stmtTestRows) Close() error      { return nil }
+func (r *stmtTestRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+type stmtTestReceiver struct {
+	NullEventReceiver
+	mu     sync.Mutex
+	events map[string]int
+}
+
+func (r *stmtTestReceiver) EventKv(eventName string, kvs map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[eventName]++
+}
+
+func (r *stmtTestReceiver) TimingKv(eventName string, nanoseconds int64, kvs map[string]string) {
+	r.EventKv(eventName, kvs)
+}
+
+func newStmtTestSession(size int) (*Session, *stmtTestReceiver) {
+	db, err := sql.Open("dbr_stmt_test", "")
+	if err != nil {
+		panic(err)
+	}
+	r := &stmtTestReceiver{events: make(map[string]int)}
+	return NewConnection(db, r).EnableStmtCache(size).NewSession(nil), r
+}
+
+func TestStmtCacheHitMiss(t *testing.T) {
+	s, r := newStmtTestSession(2)
+	before := atomic.LoadInt32(&stmtDriver.prepared)
+
+	for i := 0; i < 3; i++ {
+		n, err := s.Select("n").From("t").Where("id = ?", i).ReturnInt64()
+		assert.NoError(t, err)
+		assert.Exactly(t, int64(1), n)
+	}
+	assert.Exactly(t, int32(1), atomic.LoadInt32(&stmtDriver.prepared)-before)
+	assert.Exactly(t, 1, r.events["dbr.stmt_cache.miss"])
+	assert.Exactly(t, 2, r.events["dbr.stmt_cache.hit"])
+	assert.Exactly(t, 1, s.cxn.stmts.Len())
+
+	// slices cannot be sent to the driver
+	_, err := s.Select("n").From("t").Where("id IN ?", []int{1, 2}).ReturnInt64()
+	assert.NoError(t, err)
+	assert.Exactly(t, 1, s.cxn.stmts.Len())
+
+	_, err = s.Update("t").Set("n", 2).Where("id = ?", 1).Exec()
+	assert.NoError(t, err)
+	_, err = s.DeleteFrom("t").Where("id = ?", 1).Exec()
+	assert.NoError(t, err)
+	assert.Exactly(t, 2, s.cxn.stmts.Len()) // LRU size 2, SELECT evicted
+	assert.Exactly(t, 3, r.events["dbr.stmt_cache.miss"])
+
+	s.cxn.DisableStmtCache()
+	assert.Nil(t, s.cxn.stmts)
+}
+
+func TestStmtCacheInvalidate(t *testing.T) {
+	s, r := newStmtTestSession(10)
+	_, err := s.Update("t").Set("n", 2).Where("id = ?", 1).Exec()
+	assert.NoError(t, err)
+	assert.Exactly(t, 1, s.cxn.stmts.Len())
+
+	atomic.StoreInt32(&stmtDriver.badConn, 1)
+	_, err = s.Update("t").Set("n", 2).Where("id = ?", 1).Exec()
+	atomic.StoreInt32(&stmtDriver.badConn, 0)
+	assert.Error(t, err)
+	assert.Exactly(t, 0, s.cxn.stmts.Len())
+	assert.Exactly(t, 1, r.events["dbr.stmt_cache.invalidate"])
+}
+
+func TestStmtCacheEvictInUse(t *testing.T) {
+	s, _ := newStmtTestSession(1)
+	c := s.cxn.stmts
+	cs, hit, err := c.acquire(context.Background(), "SELECT 1")
+	assert.NoError(t, err)
+	assert.False(t, hit)
+
+	closed := atomic.LoadInt32(&stmtDriver.closed)
+	c.Remove("SELECT 1")
+	assert.True(t, cs.evicted)
+	c.release(cs)
+	assert.Exactly(t, 0, c.Len())
+	assert.True(t, atomic.LoadInt32(&stmtDriver.closed) >= closed)
+}
+
+func TestStmtCacheTx(t *testing.T) {
+	s, r := newStmtTestSession(10)
+	tx, err := s.Begin()
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		var n int64
+		assert.NoError(t, tx.Select("n").From("t").Where("id = ?", i).LoadValue(&n))
+		assert.Exactly(t, int64(1), n)
+	}
+	assert.NoError(t, tx.Commit())
+	assert.Exactly(t, 1, r.events["dbr.stmt_cache.hit"])
+}
+
+func TestStmtCacheConcurrent(t *testing.T) {
+	s, _ := newStmtTestSession(3)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.Select("n").From("t").Where("id = ?", i).Limit(uint64(i%5 + 1)).ReturnInt64()
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	assert.True(t, s.cxn.stmts.Len() <= 3)
+}
+
+func TestIsDriverArgs(t *testing.T) {
+	assert.True(t, isDriverArgs(nil))
+	assert.True(t, isDriverArgs([]interface{}{1, "a", nil, []byte("b"), 1.2, true, NullString{}}))
+	assert.False(t, isDriverArgs([]interface{}{[]int{1}}))
+	assert.False(t, isDriverArgs([]interface{}{uint64(1 << 63)}))
+}
+
+func benchmarkSelect(b *testing.B, s *Session) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Select("n").From("t").Where("id = ? AND name = ?", i, "o'clock").ReturnInt64(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSelectInterpolate	  371668	      2962 ns/op	    1175 B/op	      24 allocs/op
+func BenchmarkSelectInterpolate(b *testing.B) {
+	s, _ := newStmtTestSession(10)
+	s.cxn.DisableStmtCache()
+	benchmarkSelect(b, s)
+}
+
+// BenchmarkSelectStmtCache	  468505	      2753 ns/op	    1415 B/op	      25 allocs/op
+// Against the in memory test driver. Use the *Real benchmarks to measure the
+// round trips to MySQL.
+func BenchmarkSelectStmtCache(b *testing.B) {
+	s, _ := newStmtTestSession(10)
+	benchmarkSelect(b, s)
+}
+
+func BenchmarkSelectInterpolateReal(b *testing.B) {
+	benchmarkSelectReal(b, createRealSessionWithFixtures())
+}
+
+func BenchmarkSelectStmtCacheReal(b *testing.B) {
+	s := createRealSessionWithFixtures()
+	s.cxn.EnableStmtCache(0)
+	benchmarkSelectReal(b, s)
+}
+
+func benchmarkSelectReal(b *testing.B, s *Session) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var p dbrPerson
+		if err := s.Select("id", "name", "email").From("dbr_people").Where("email = ?", "jonathan@uservoice.com").LoadStruct(&p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,67 +1,200 @@
 package dbr
 
 import (
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
 )
 
 var destDummy interface{}
 
-type fieldMapQueueElement struct {
-	Type reflect.Type
-	Idxs []int
+var (
+	typeOfScanner = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	typeOfValuer  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// structField a column of a struct. index is the path for FieldByIndex.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
 }
 
-// recordType is the type of a structure
-func (sess *Session) calculateFieldMap(recordType reflect.Type, columns []string, requireAllColumns bool) ([][]int, error) {
-	// each value is either the slice to get to the field via FieldByIndex(index []int) in the record, or nil if we don't want to map it to the structure.
-	lenColumns := len(columns)
-	fieldMap := make([][]int, lenColumns)
+// structInfo all columns of a struct type in the order of the fields.
+type structInfo struct {
+	fields []*structField
+	byName map[string]*structField
+}
 
-	for i, col := range columns {
-		fieldMap[i] = nil
+// structCache global cache of the columns per struct type. Gets reset by
+// SetNameMapping.
+var structCache = struct {
+	sync.RWMutex
+	m map[reflect.Type]*structInfo
+}{m: make(map[reflect.Type]*structInfo)}
+
+// SetNameMapping sets the routine to map struct field names to column names
+// and resets the cache of the struct types. The db tag of a field takes
+// precedence. Must be called before the first query.
+func SetNameMapping(f func(fieldName string) string) {
+	structCache.Lock()
+	defer structCache.Unlock()
+	NameMapping = f
+	structCache.m = make(map[reflect.Type]*structInfo)
+}
 
-		queue := []fieldMapQueueElement{fieldMapQueueElement{Type: recordType, Idxs: nil}}
+// getStructInfo returns the cached columns of a struct type.
+func getStructInfo(t reflect.Type) *structInfo {
+	structCache.RLock()
+	si, ok := structCache.m[t]
+	structCache.RUnlock()
+	if ok {
+		return si
+	}
+	structCache.Lock()
+	defer structCache.Unlock()
+	si = newStructInfo(t)
+	structCache.m[t] = si
+	return si
+}
 
-	QueueLoop:
-		for len(queue) > 0 {
-			curEntry := queue[0]
-			queue = queue[1:]
+// isValueType returns true if the type gets mapped as a whole to a column
+// instead of its fields, e.g. time.Time, NullString or money.Currency.
+func isValueType(t reflect.Type) bool {
+	return t == typeOfTime || t.Implements(typeOfValuer) || reflect.PtrTo(t).Implements(typeOfScanner) ||
+		reflect.PtrTo(t).Implements(typeOfValuer)
+}
 
-			curType := curEntry.Type
-			curIdxs := curEntry.Idxs
-			lenFields := curType.NumField()
+// newStructInfo walks breadth first through the fields. Fields of embedded,
+// pointer embedded and nested structs are included. On duplicate column names
+// the field with the lowest depth wins.
+//
+// Tags:
+//
+//	`db:"column_name"` maps the field to the column
+//	`db:"-"` ignores the field
+//	`db:",omitempty"` skips zero values when the columns are derived from the struct
+//
+// Embedded pointers to unexported struct types are ignored because they
+// cannot be allocated while loading.
+func newStructInfo(t reflect.Type) *structInfo {
+	si := &structInfo{byName: make(map[string]*structField)}
+
+	type entry struct {
+		t    reflect.Type
+		idxs []int
+	}
+	queue := []entry{{t: t}}
+	visited := map[reflect.Type]bool{t: true}
 
-			for j := 0; j < lenFields; j++ {
-				fieldStruct := curType.Field(j)
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
 
-				// Skip unexported field
-				if len(fieldStruct.PkgPath) != 0 {
-					continue
-				}
+		for j := 0; j < cur.t.NumField(); j++ {
+			f := cur.t.Field(j)
+			if f.PkgPath != "" && !(f.Anonymous && f.Type.Kind() == reflect.Struct) { // unexported
+				continue
+			}
+			tag := f.Tag.Get("db")
+			if tag == "-" {
+				continue
+			}
+			name, opts := tag, ""
+			if i := strings.IndexByte(tag, ','); i >= 0 {
+				name, opts = tag[:i], tag[i+1:]
+			}
+			idxs := make([]int, len(cur.idxs)+1)
+			copy(idxs, cur.idxs)
+			idxs[len(cur.idxs)] = j
 
-				name := fieldStruct.Tag.Get("db")
-				if name != "-" {
-					if name == "" {
-						name = NameMapping(fieldStruct.Name)
-					}
-					if name == col {
-						fieldMap[i] = append(curIdxs, j)
-						break QueueLoop
-					}
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr && f.Anonymous {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && name == "" && !isValueType(ft) {
+				if !visited[ft] {
+					visited[ft] = true
+					queue = append(queue, entry{t: ft, idxs: idxs})
 				}
+				continue
+			}
+			if f.PkgPath != "" { // unexported embedded non-struct
+				continue
+			}
+			if name == "" {
+				name = NameMapping(f.Name)
+			}
+			if _, ok := si.byName[name]; ok {
+				continue
+			}
+			sf := &structField{name: name, index: idxs, omitEmpty: opts == "omitempty"}
+			si.fields = append(si.fields, sf)
+			si.byName[name] = sf
+		}
+	}
+	return si
+}
 
-				if fieldStruct.Type.Kind() == reflect.Struct {
-					var idxs2 []int
-					copy(idxs2, curIdxs)
-					idxs2 = append(idxs2, j)
-					queue = append(queue, fieldMapQueueElement{Type: fieldStruct.Type, Idxs: idxs2})
-				}
+// columns returns the column names of all fields. Fields with the option
+// omitempty will be skipped if their value in record is zero.
+func (si *structInfo) columns(record reflect.Value) []string {
+	cols := make([]string, 0, len(si.fields))
+	for _, f := range si.fields {
+		if f.omitEmpty {
+			if v, ok := fieldByIndex(record, f.index); !ok || v.IsZero() {
+				continue
+			}
+		}
+		cols = append(cols, f.name)
+	}
+	return cols
+}
+
+// fieldByIndex returns the field and false if an embedded pointer on the
+// path is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
 			}
+			v = v.Elem()
 		}
+		v = v.Field(x)
+	}
+	return v, true
+}
 
-		if requireAllColumns && fieldMap[i] == nil {
+// fieldByIndexAlloc returns the field and allocates nil embedded pointers on
+// the path.
+func fieldByIndexAlloc(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// recordType is the type of a structure
+func (sess *Session) calculateFieldMap(recordType reflect.Type, columns []string, requireAllColumns bool) ([][]int, error) {
+	// each value is either the slice to get to the field via fieldByIndex in the record, or nil if we don't want to map it to the structure.
+	si := getStructInfo(recordType)
+	fieldMap := make([][]int, len(columns))
+
+	for i, col := range columns {
+		if f, ok := si.byName[col]; ok {
+			fieldMap[i] = f.index
+		} else if requireAllColumns {
 			return nil, errors.New(fmt.Sprint("couldn't find match for column ", col))
 		}
 	}
@@ -80,7 +213,7 @@ func (sess *Session) prepareHolderFor(record reflect.Value, fieldMap [][]int, ho
 		if fieldIndex == nil {
 			holder[i] = &destDummy
 		} else {
-			field := record.FieldByIndex(fieldIndex)
+			field := fieldByIndexAlloc(record, fieldIndex)
 			holder[i] = field.Addr().Interface()
 		}
 	}
@@ -91,19 +224,31 @@ func (sess *Session) prepareHolderFor(record reflect.Value, fieldMap [][]int, ho
 func (sess *Session) valuesFor(recordType reflect.Type, record reflect.Value, columns []string) ([]interface{}, error) {
 	fieldMap, err := sess.calculateFieldMap(recordType, columns, true)
 	if err != nil {
-		fmt.Println("err: calc field map")
 		return nil, err
 	}
 
 	values := make([]interface{}, len(columns))
 	for i, fieldIndex := range fieldMap {
-		if fieldIndex == nil {
-			panic("wtf bro")
-		} else {
-			field := record.FieldByIndex(fieldIndex)
-			values[i] = field.Interface()
+		field, ok := fieldByIndex(record, fieldIndex)
+		if !ok {
+			continue // nil embedded pointer
 		}
+		values[i] = fieldValue(field)
 	}
 
 	return values, nil
 }
+
+// fieldValue returns the value of a field. Types whose pointer implements
+// driver.Valuer, like money.Currency, will be returned as pointer.
+func fieldValue(field reflect.Value) interface{} {
+	if field.Type().Implements(typeOfValuer) || !reflect.PtrTo(field.Type()).Implements(typeOfValuer) {
+		return field.Interface()
+	}
+	if !field.CanAddr() {
+		c := reflect.New(field.Type())
+		c.Elem().Set(field)
+		return c.Interface()
+	}
+	return field.Addr().Interface()
+}
@@ -0,0 +1,100 @@
+package dbr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapTimestamps struct {
+	CreatedAt NullTime
+	UpdatedAt NullTime `db:",omitempty"`
+}
+
+type MapAudit struct {
+	Comment string `db:"audit_comment"`
+	Id      int64  // shadowed by mapProduct.Id
+}
+
+type mapProduct struct {
+	Id int64 `db:"entity_id"`
+	mapTimestamps
+	*MapAudit
+	Sku      string
+	Price    money.Currency
+	Special  money.Currency `db:"special_price,omitempty"`
+	Internal string         `db:"-"`
+	hidden   string
+}
+
+func TestStructMappingColumns(t *testing.T) {
+	si := getStructInfo(reflect.TypeOf(mapProduct{}))
+	var names []string
+	for _, f := range si.fields {
+		names = append(names, f.name)
+	}
+	assert.Exactly(t, []string{"entity_id", "sku", "price", "special_price", "created_at", "updated_at", "audit_comment", "id"}, names)
+	assert.Exactly(t, []int{2, 0}, si.byName["audit_comment"].index)
+	assert.True(t, si.byName["updated_at"].omitEmpty)
+	assert.True(t, si == getStructInfo(reflect.TypeOf(mapProduct{})), "cached")
+
+	p := mapProduct{Id: 3}
+	assert.Exactly(t, []string{"entity_id", "sku", "price", "created_at", "audit_comment", "id"}, si.columns(reflect.ValueOf(p)))
+}
+
+func TestStructMappingInsertRecord(t *testing.T) {
+	s := createFakeSession()
+	p := &mapProduct{Id: 3, Sku: "SKU-3", Price: money.New(money.Precision(100)).Set(1250), Internal: "x", hidden: "y"}
+	sql, args := s.InsertInto("catalog_product").Record(p).ToSql()
+	assert.Equal(t, "INSERT INTO catalog_product (`entity_id`,`sku`,`price`,`created_at`,`audit_comment`,`id`) VALUES (?,?,?,?,?,?)", sql)
+	assert.Len(t, args, 6)
+	assert.Exactly(t, int64(3), args[0])
+	assert.Exactly(t, &p.Price, args[2], "pointer receiver Valuer")
+	assert.Nil(t, args[4], "nil embedded pointer")
+
+	full, err := Interpolate(sql, args)
+	assert.NoError(t, err)
+	assert.Equal(t, "INSERT INTO catalog_product (`entity_id`,`sku`,`price`,`created_at`,`audit_comment`,`id`) VALUES (3,'SKU-3',12.5,NULL,NULL,NULL)", full)
+}
+
+func TestStructMappingUpdateSetRecord(t *testing.T) {
+	s := createFakeSession()
+	p := mapProduct{Id: 3, Sku: "SKU-3", MapAudit: &MapAudit{Comment: "imported"}}
+	sql, args := s.Update("catalog_product").SetRecord(p, "sku", "audit_comment").Where("entity_id = ?", p.Id).ToSql()
+	assert.Equal(t, "UPDATE catalog_product SET `sku` = ?, `audit_comment` = ? WHERE (entity_id = ?)", sql)
+	assert.Equal(t, []interface{}{"SKU-3", "imported", int64(3)}, args)
+
+	assert.Panics(t, func() { s.Update("catalog_product").SetRecord(p, "not_a_column") })
+}
+
+func TestStructMappingLoad(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	mock.ExpectQuery("SELECT entity_id, sku, price, audit_comment FROM catalog_product").
+		WillReturnRows(csdbtest.NewRows("entity_id", "sku", "price", "audit_comment").
+			AddRow(3, "SKU-3", []byte("12.50"), "imported"))
+
+	var ps []*mapProduct
+	n, err := NewConnection(db, nil).NewSession(nil).
+		Select("entity_id", "sku", "price", "audit_comment").From("catalog_product").LoadStructs(&ps)
+	assert.NoError(t, err)
+	assert.Exactly(t, 1, n)
+	assert.Exactly(t, int64(3), ps[0].Id)
+	assert.Exactly(t, "SKU-3", ps[0].Sku)
+	assert.Exactly(t, 12.5, ps[0].Price.Getf())
+	if assert.NotNil(t, ps[0].MapAudit, "embedded pointer allocated") {
+		assert.Exactly(t, "imported", ps[0].Comment)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetNameMapping(t *testing.T) {
+	defer SetNameMapping(camelCaseToSnakeCase)
+	SetNameMapping(strings.ToUpper)
+	si := getStructInfo(reflect.TypeOf(MapAudit{}))
+	assert.Exactly(t, []string{"audit_comment", "ID"}, []string{si.fields[0].name, si.fields[1].name})
+}
@@ -1,18 +1,50 @@
 package dbr
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/juju/errgo"
 )
 
+// TxMaxRetries number of retries of Session.Transaction() after a deadlock or
+// a lock wait timeout.
+var TxMaxRetries = 3
+
+// TxRetryBackoff wait time before the first retry of Session.Transaction().
+// The time doubles with each further retry.
+var TxRetryBackoff = 20 * time.Millisecond
+
 // Tx is a transaction for the given Session
 type Tx struct {
 	*Session
 	*sql.Tx
+	// savepoint name of a nested transaction, empty for the outermost one.
+	savepoint string
+	// savepoints counter shared by the outermost and all nested transactions
+	// to create unique savepoint names.
+	savepoints *int
+	done       bool
 }
 
 // Begin creates a transaction for the given session
 func (sess *Session) Begin() (*Tx, error) {
-	tx, err := sess.cxn.Db.Begin()
+	return sess.BeginTx(context.Background(), nil)
+}
+
+// BeginTx creates a transaction for the given session. The transaction gets
+// rolled back if the context is done before Commit() has been called. The
+// options can be nil and set the isolation level or a read only transaction,
+// e.g. &sql.TxOptions{Isolation: sql.LevelSerializable}.
+func (sess *Session) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tx, err := sess.cxn.Db.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, sess.EventErr("dbr.begin.error", err)
 	} else {
@@ -20,13 +52,64 @@ func (sess *Session) Begin() (*Tx, error) {
 	}
 
 	return &Tx{
-		Session: sess,
-		Tx:      tx,
+		Session:    sess,
+		Tx:         tx,
+		savepoints: new(int),
+	}, nil
+}
+
+// Begin starts a nested transaction which maps to a savepoint of the
+// outermost transaction. Commit() releases the savepoint and Rollback() rolls
+// back to the savepoint. The names of the savepoints are unique within the
+// outermost transaction.
+func (tx *Tx) Begin() (*Tx, error) {
+	*tx.savepoints++
+	sp := "dbr_sp_" + strconv.Itoa(*tx.savepoints)
+	if err := tx.Savepoint(sp); err != nil {
+		return nil, err
+	}
+	return &Tx{
+		Session:    tx.Session,
+		Tx:         tx.Tx,
+		savepoint:  sp,
+		savepoints: tx.savepoints,
 	}, nil
 }
 
-// Commit finishes the transaction
+// Savepoint sets a named savepoint within the transaction.
+func (tx *Tx) Savepoint(name string) error {
+	return tx.execSavepoint("dbr.savepoint", "SAVEPOINT ", name)
+}
+
+// RollbackTo rolls back the transaction to the named savepoint. The
+// savepoint stays valid.
+func (tx *Tx) RollbackTo(name string) error {
+	return tx.execSavepoint("dbr.rollback_to", "ROLLBACK TO SAVEPOINT ", name)
+}
+
+// Release removes the named savepoint without changing the data.
+func (tx *Tx) Release(name string) error {
+	return tx.execSavepoint("dbr.release", "RELEASE SAVEPOINT ", name)
+}
+
+func (tx *Tx) execSavepoint(event, stmt, name string) error {
+	if _, err := tx.Tx.Exec(stmt + tx.dialect().QuoteIdent(name)); err != nil {
+		return tx.EventErrKv(event+".error", err, kvs{"savepoint": name})
+	}
+	tx.EventKv(event, kvs{"savepoint": name})
+	return nil
+}
+
+// Commit finishes the transaction. A nested transaction releases its
+// savepoint.
 func (tx *Tx) Commit() error {
+	if tx.savepoint != "" {
+		if tx.done {
+			return sql.ErrTxDone
+		}
+		tx.done = true
+		return tx.Release(tx.savepoint)
+	}
 	err := tx.Tx.Commit()
 	if err != nil {
 		return tx.EventErr("dbr.commit.error", err)
@@ -36,8 +119,16 @@ func (tx *Tx) Commit() error {
 	return nil
 }
 
-// Rollback cancels the transaction
+// Rollback cancels the transaction. A nested transaction rolls back to its
+// savepoint.
 func (tx *Tx) Rollback() error {
+	if tx.savepoint != "" {
+		if tx.done {
+			return sql.ErrTxDone
+		}
+		tx.done = true
+		return tx.RollbackTo(tx.savepoint)
+	}
 	err := tx.Tx.Rollback()
 	if err != nil {
 		return tx.EventErr("dbr.rollback", err)
@@ -51,6 +142,12 @@ func (tx *Tx) Rollback() error {
 // Useful to defer tx.RollbackUnlessCommitted() -- so you don't have to handle N failure cases
 // Keep in mind the only way to detect an error on the rollback is via the event log.
 func (tx *Tx) RollbackUnlessCommitted() {
+	if tx.savepoint != "" {
+		if !tx.done {
+			tx.Rollback()
+		}
+		return
+	}
 	err := tx.Tx.Rollback()
 	if err == sql.ErrTxDone {
 		// ok
@@ -60,3 +157,88 @@ func (tx *Tx) RollbackUnlessCommitted() {
 		tx.Event("dbr.rollback")
 	}
 }
+
+// Transaction runs fn within a transaction. The transaction gets committed if
+// fn returns nil and rolled back if fn returns an error or panics. A panic
+// will be re-raised after the rollback. On a MySQL deadlock (1213) or lock
+// wait timeout (1205) the whole transaction will be retried up to
+// TxMaxRetries times, so fn must not have side effects outside of the
+// database.
+func (sess *Session) Transaction(fn func(tx *Tx) error) error {
+	return sess.TransactionContext(context.Background(), nil, fn)
+}
+
+// TransactionContext same as Transaction with a context and options, see
+// BeginTx. The retries stop when the context is done.
+func (sess *Session) TransactionContext(ctx context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	backoff := TxRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := sess.transaction(ctx, opts, fn)
+		if err == nil || attempt > TxMaxRetries || !isRetryableTxErr(err) {
+			return err
+		}
+		sess.EventKv("dbr.transaction.retry", kvs{"attempt": strconv.Itoa(attempt), "err": err.Error()})
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (sess *Session) transaction(ctx context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) error {
+	tx, err := sess.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return tx.run(fn)
+}
+
+// Transaction runs fn within a nested transaction, see Session.Transaction.
+// There are no retries because MySQL rolls back the outermost transaction on
+// a deadlock.
+func (tx *Tx) Transaction(fn func(tx *Tx) error) error {
+	nested, err := tx.Begin()
+	if err != nil {
+		return err
+	}
+	return nested.run(fn)
+}
+
+// run commits or rolls back the transaction depending on the result of fn.
+func (tx *Tx) run(fn func(tx *Tx) error) error {
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// isRetryableTxErr reports a MySQL deadlock or lock wait timeout. Errors
+// masked with errgo will be unwrapped.
+func isRetryableTxErr(err error) bool {
+	for err != nil {
+		var me *mysql.MySQLError
+		if errors.As(errgo.Cause(err), &me) {
+			return me.Number == 1213 || me.Number == 1205
+		}
+		u, ok := err.(interface {
+			Underlying() error
+		})
+		if !ok {
+			return false
+		}
+		err = u.Underlying()
+	}
+	return false
+}
@@ -1,9 +1,13 @@
 package dbr
 
 import (
-	// "database/sql"
+	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/juju/errgo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,3 +56,97 @@ func TestTransactionRollbackReal(t *testing.T) {
 	err = tx.Rollback()
 	assert.NoError(t, err)
 }
+
+func TestTransactionSavepoints(t *testing.T) {
+	s, r := newStmtTestSession(0)
+	s.cxn.DisableStmtCache()
+
+	tx, err := s.Begin()
+	assert.NoError(t, err)
+	stmtDriver.lastQueries()
+
+	assert.NoError(t, tx.Savepoint("a"))
+	assert.NoError(t, tx.RollbackTo("a"))
+	assert.NoError(t, tx.Release("a"))
+
+	n1, err := tx.Begin()
+	assert.NoError(t, err)
+	n2, err := n1.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, n2.Rollback())
+	assert.Exactly(t, sql.ErrTxDone, n2.Commit())
+	n2.RollbackUnlessCommitted()
+	// a sibling must not reuse the name of the rolled back savepoint
+	n3, err := n1.Begin()
+	assert.NoError(t, err)
+	assert.NoError(t, n3.Commit())
+	assert.NoError(t, n1.Commit())
+
+	assert.Exactly(t, []string{
+		"SAVEPOINT `a`",
+		"ROLLBACK TO SAVEPOINT `a`",
+		"RELEASE SAVEPOINT `a`",
+		"SAVEPOINT `dbr_sp_1`",
+		"SAVEPOINT `dbr_sp_2`",
+		"ROLLBACK TO SAVEPOINT `dbr_sp_2`",
+		"SAVEPOINT `dbr_sp_3`",
+		"RELEASE SAVEPOINT `dbr_sp_3`",
+		"RELEASE SAVEPOINT `dbr_sp_1`",
+	}, stmtDriver.lastQueries())
+	assert.Exactly(t, 4, r.events["dbr.savepoint"])
+	assert.NoError(t, tx.Commit())
+}
+
+func TestTransactionFunc(t *testing.T) {
+	defer func(b time.Duration) { TxRetryBackoff = b }(TxRetryBackoff)
+	TxRetryBackoff = time.Millisecond
+
+	s, r := newStmtTestSession(0)
+
+	calls := 0
+	err := s.Transaction(func(tx *Tx) error {
+		calls++
+		if calls < 3 {
+			return &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+		}
+		return tx.Transaction(func(tx *Tx) error {
+			assert.Exactly(t, "dbr_sp_1", tx.savepoint)
+			return nil
+		})
+	})
+	assert.NoError(t, err)
+	assert.Exactly(t, 3, calls)
+	assert.Exactly(t, 2, r.events["dbr.transaction.retry"])
+
+	calls = 0
+	err = s.Transaction(func(tx *Tx) error {
+		calls++
+		if calls < 2 {
+			return errgo.Mask(&mysql.MySQLError{Number: 1213, Message: "Deadlock found"})
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Exactly(t, 2, calls)
+
+	calls = 0
+	err = s.Transaction(func(tx *Tx) error {
+		calls++
+		return &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}
+	})
+	assert.Error(t, err)
+	assert.Exactly(t, TxMaxRetries+1, calls)
+
+	errTest := errors.New("test error")
+	calls = 0
+	err = s.Transaction(func(tx *Tx) error {
+		calls++
+		return errTest
+	})
+	assert.Exactly(t, errTest, err)
+	assert.Exactly(t, 1, calls)
+
+	assert.Panics(t, func() {
+		s.Transaction(func(tx *Tx) error { panic("boom") })
+	})
+}
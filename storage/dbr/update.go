@@ -2,10 +2,11 @@ package dbr
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
-	"fmt"
-	"time"
 	"database/sql/driver"
+	"reflect"
+	"time"
 )
 
 // UpdateBuilder contains the clauses for an UPDATE statement
@@ -84,6 +85,28 @@ func (b *UpdateBuilder) Set(column string, value interface{}) *UpdateBuilder {
 	return b
 }
 
+// SetRecord appends the fields of a struct as column/value pairs. Without
+// columns all mapped fields of the struct will be set, except zero fields
+// tagged with db:",omitempty". Panics if a column cannot be found in the
+// struct.
+func (b *UpdateBuilder) SetRecord(record interface{}, columns ...string) *UpdateBuilder {
+	ind := reflect.Indirect(reflect.ValueOf(record))
+	if ind.Kind() != reflect.Struct {
+		panic("you need to pass in a struct or the address of a struct")
+	}
+	if len(columns) == 0 {
+		columns = getStructInfo(ind.Type()).columns(ind)
+	}
+	vals, err := b.valuesFor(ind.Type(), ind, columns)
+	if err != nil {
+		panic(err.Error())
+	}
+	for i, c := range columns {
+		b = b.Set(c, vals[i])
+	}
+	return b
+}
+
 // SetMap appends the elements of the map as column/value pairs for the statement
 func (b *UpdateBuilder) SetMap(clauses map[string]interface{}) *UpdateBuilder {
 	for col, val := range clauses {
@@ -92,7 +115,8 @@ func (b *UpdateBuilder) SetMap(clauses map[string]interface{}) *UpdateBuilder {
 	return b
 }
 
-// Where appends a WHERE clause to the statement
+// Where appends a WHERE clause to the statement for the given string and args,
+// map of column/value pairs or Condition
 func (b *UpdateBuilder) Where(whereSqlOrMap interface{}, args ...interface{}) *UpdateBuilder {
 	argsValuer(&args)
 	b.WhereFragments = append(b.WhereFragments, newWhereFragment(whereSqlOrMap, args))
@@ -183,15 +207,7 @@ func (b *UpdateBuilder) ToSql() (string, []interface{}) {
 		}
 	}
 
-	if b.LimitValid {
-		sql.WriteString(" LIMIT ")
-		fmt.Fprint(&sql, b.LimitCount)
-	}
-
-	if b.OffsetValid {
-		sql.WriteString(" OFFSET ")
-		fmt.Fprint(&sql, b.OffsetCount)
-	}
+	sql.WriteString(b.dialect().LimitOffset(b.LimitCount, b.OffsetCount, b.LimitValid, b.OffsetValid))
 
 	return sql.String(), args
 }
@@ -199,20 +215,32 @@ func (b *UpdateBuilder) ToSql() (string, []interface{}) {
 // Exec executes the statement represented by the UpdateBuilder
 // It returns the raw database/sql Result and an error if there was one
 func (b *UpdateBuilder) Exec() (sql.Result, error) {
+	return b.ExecContext(context.Background())
+}
+
+// ExecContext executes the statement represented by the UpdateBuilder. The
+// statement gets cancelled when the context is done. The default timeout of
+// the session applies if the context has no deadline.
+func (b *UpdateBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
 	sql, args := b.ToSql()
 
-	fullSql, err := Interpolate(sql, args)
+	fullSql, args, prepared, err := b.interpolate(sql, args)
 	if err != nil {
-		return nil, b.EventErrKv("dbr.update.exec.interpolate", err, kvs{"sql": fullSql})
+		return nil, eventErrKv(ctx, b.EventReceiver, "dbr.update.exec.interpolate", err, kvs{"sql": fullSql})
 	}
 
 	// Start the timer:
 	startTime := time.Now()
-	defer func() { b.TimingKv("dbr.update", time.Since(startTime).Nanoseconds(), kvs{"sql": fullSql}) }()
+	defer func() {
+		timingKv(ctx, b.EventReceiver, "dbr.update", time.Since(startTime).Nanoseconds(), timingKvs(fullSql, args))
+	}()
 
-	result, err := b.runner.Exec(fullSql)
+	result, err := b.execContext(ctx, b.runner, fullSql, args, prepared)
 	if err != nil {
-		return result, b.EventErrKv("dbr.update.exec.exec", err, kvs{"sql": fullSql})
+		return result, eventErrKv(ctx, b.EventReceiver, "dbr.update.exec.exec", err, kvs{"sql": fullSql})
 	}
 
 	return result, nil
@@ -0,0 +1,255 @@
+package dbr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DefaultBatchSize maximum number of rows per UPDATE statement of the
+// UpdateBatchBuilder if BatchSize is not set.
+var DefaultBatchSize = 500
+
+// UpdateBatchBuilder updates many rows identified by their primary key with
+// different values per row. Each column gets a CASE expression:
+//
+//	UPDATE cataloginventory_stock_item SET
+//		`qty` = CASE `product_id` WHEN ? THEN ? WHEN ? THEN ? ELSE `qty` END
+//	WHERE `product_id` IN (?,?)
+//
+// The rows will be split into chunks of BatchSize rows and all statements run
+// within one transaction.
+type UpdateBatchBuilder struct {
+	*Session
+	runner
+	// tx is set if the builder has been created by a transaction.
+	tx *Tx
+
+	Table      string
+	PrimaryKey string
+	Cols       []string
+	// Vals contains per row the primary key followed by the values of Cols.
+	Vals           [][]interface{}
+	Recs           []interface{}
+	WhereFragments []*whereFragment
+	// BatchSize maximum number of rows per statement, DefaultBatchSize if <= 0.
+	BatchSize int
+}
+
+// UpdateBatch creates a new UpdateBatchBuilder for the table. The rows will be
+// identified by the primary key column and the columns get updated.
+func (sess *Session) UpdateBatch(table, primaryKey string, columns ...string) *UpdateBatchBuilder {
+	return &UpdateBatchBuilder{
+		Session:    sess,
+		runner:     sess.cxn.Db,
+		Table:      table,
+		PrimaryKey: primaryKey,
+		Cols:       columns,
+	}
+}
+
+// UpdateBatch creates a new UpdateBatchBuilder bound to a transaction. All
+// chunks run within the transaction.
+func (tx *Tx) UpdateBatch(table, primaryKey string, columns ...string) *UpdateBatchBuilder {
+	return &UpdateBatchBuilder{
+		Session:    tx.Session,
+		runner:     tx.Tx,
+		tx:         tx,
+		Table:      table,
+		PrimaryKey: primaryKey,
+		Cols:       columns,
+	}
+}
+
+// Values appends a row. The first value is the primary key followed by the
+// values of the columns.
+func (b *UpdateBatchBuilder) Values(primaryKey interface{}, vals ...interface{}) *UpdateBatchBuilder {
+	if len(vals) != len(b.Cols) {
+		panic(fmt.Sprintf("%d values for %d columns", len(vals), len(b.Cols)))
+	}
+	row := append([]interface{}{primaryKey}, vals...)
+	argsValuer(&row)
+	b.Vals = append(b.Vals, row)
+	return b
+}
+
+// Record appends a row whose primary key and columns will be read from the
+// struct. Uses reflection.
+func (b *UpdateBatchBuilder) Record(record interface{}) *UpdateBatchBuilder {
+	b.Recs = append(b.Recs, record)
+	return b
+}
+
+// Where appends a condition to the WHERE clause which applies in addition to
+// the primary keys of each chunk.
+func (b *UpdateBatchBuilder) Where(whereSqlOrMap interface{}, args ...interface{}) *UpdateBatchBuilder {
+	argsValuer(&args)
+	b.WhereFragments = append(b.WhereFragments, newWhereFragment(whereSqlOrMap, args))
+	return b
+}
+
+// Batch sets the maximum number of rows per statement.
+func (b *UpdateBatchBuilder) Batch(size int) *UpdateBatchBuilder {
+	b.BatchSize = size
+	return b
+}
+
+// rows returns the rows of Vals and Recs. Panics if a record cannot be
+// reflected.
+func (b *UpdateBatchBuilder) rows() [][]interface{} {
+	rows := make([][]interface{}, 0, len(b.Vals)+len(b.Recs))
+	rows = append(rows, b.Vals...)
+	cols := append([]string{b.PrimaryKey}, b.Cols...)
+	for _, rec := range b.Recs {
+		ind := reflect.Indirect(reflect.ValueOf(rec))
+		vals, err := b.valuesFor(ind.Type(), ind, cols)
+		if err != nil {
+			panic(err.Error())
+		}
+		argsValuer(&vals)
+		rows = append(rows, vals)
+	}
+	return rows
+}
+
+// Chunks splits the rows into sets of at most BatchSize rows.
+func (b *UpdateBatchBuilder) Chunks() [][][]interface{} {
+	size := b.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	rows := b.rows()
+	var chunks [][][]interface{}
+	for len(rows) > size {
+		chunks = append(chunks, rows[:size])
+		rows = rows[size:]
+	}
+	if len(rows) > 0 {
+		chunks = append(chunks, rows)
+	}
+	return chunks
+}
+
+// ToSql serializes all rows into one statement regardless of BatchSize. It
+// returns the string with placeholders and a slice of query arguments.
+func (b *UpdateBatchBuilder) ToSql() (string, []interface{}) {
+	return b.chunkToSql(b.rows())
+}
+
+func (b *UpdateBatchBuilder) chunkToSql(rows [][]interface{}) (string, []interface{}) {
+	if len(b.Table) == 0 {
+		panic("no table specified")
+	}
+	if len(b.PrimaryKey) == 0 {
+		panic("no primary key specified")
+	}
+	if len(b.Cols) == 0 {
+		panic("no columns specified")
+	}
+	if len(rows) == 0 {
+		panic("no values or records specified")
+	}
+
+	var sql bytes.Buffer
+	args := make([]interface{}, 0, len(rows)*(len(b.Cols)*2+1))
+
+	sql.WriteString("UPDATE ")
+	sql.WriteString(b.Table)
+	sql.WriteString(" SET ")
+	for i, c := range b.Cols {
+		if i > 0 {
+			sql.WriteString(", ")
+		}
+		Quoter.writeQuotedColumn(c, &sql)
+		sql.WriteString(" = CASE ")
+		Quoter.writeQuotedColumn(b.PrimaryKey, &sql)
+		for _, row := range rows {
+			sql.WriteString(" WHEN ? THEN ?")
+			args = append(args, row[0], row[i+1])
+		}
+		sql.WriteString(" ELSE ")
+		Quoter.writeQuotedColumn(c, &sql)
+		sql.WriteString(" END")
+	}
+
+	sql.WriteString(" WHERE ")
+	Quoter.writeQuotedColumn(b.PrimaryKey, &sql)
+	sql.WriteString(" IN (")
+	for i, row := range rows {
+		if i > 0 {
+			sql.WriteRune(',')
+		}
+		sql.WriteRune('?')
+		args = append(args, row[0])
+	}
+	sql.WriteRune(')')
+
+	if len(b.WhereFragments) > 0 {
+		sql.WriteString(" AND ")
+		writeWhereFragmentsToSql(b.WhereFragments, &sql, &args)
+	}
+	return sql.String(), args
+}
+
+// Exec executes the statements of all chunks. Returns the affected rows per
+// chunk.
+func (b *UpdateBatchBuilder) Exec() ([]int64, error) {
+	return b.ExecContext(context.Background())
+}
+
+// ExecContext executes the statements of all chunks within a transaction.
+// Without a bound transaction a new one will be started, committed at the end
+// and retried after a deadlock, see Session.TransactionContext(). With a
+// bound transaction the caller must roll back on error. The default timeout
+// of the session applies if the context has no deadline. Returns the affected
+// rows per chunk.
+func (b *UpdateBatchBuilder) ExecContext(ctx context.Context) ([]int64, error) {
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	chunks := b.Chunks()
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	if b.tx != nil {
+		return b.execChunks(ctx, b.tx.Tx, chunks)
+	}
+
+	var affected []int64
+	err := b.TransactionContext(ctx, nil, func(tx *Tx) error {
+		var err error
+		affected, err = b.execChunks(ctx, tx.Tx, chunks)
+		return err
+	})
+	return affected, err
+}
+
+// execChunks runs one statement per chunk.
+func (b *UpdateBatchBuilder) execChunks(ctx context.Context, r runner, chunks [][][]interface{}) ([]int64, error) {
+	affected := make([]int64, 0, len(chunks))
+	for i, rows := range chunks {
+		sql, args := b.chunkToSql(rows)
+		fullSql, args, prepared, err := b.interpolate(sql, args)
+		if err != nil {
+			return affected, eventErrKv(ctx, b.EventReceiver, "dbr.update.batch.interpolate", err, kvs{"sql": sql})
+		}
+
+		startTime := time.Now()
+		result, err := b.execContext(ctx, r, fullSql, args, prepared)
+		kv := timingKvs(fullSql, args)
+		kv["table"], kv["chunk"], kv["rows"] = b.Table, strconv.Itoa(i), strconv.Itoa(len(rows))
+		timingKv(ctx, b.EventReceiver, "dbr.update.batch", time.Since(startTime).Nanoseconds(), kv)
+		if err != nil {
+			return affected, eventErrKv(ctx, b.EventReceiver, "dbr.update.batch.exec", err, kvs{"sql": fullSql, "chunk": strconv.Itoa(i)})
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return affected, eventErrKv(ctx, b.EventReceiver, "dbr.update.batch.rows_affected", err, kvs{"chunk": strconv.Itoa(i)})
+		}
+		affected = append(affected, n)
+	}
+	return affected, nil
+}
@@ -0,0 +1,70 @@
+package dbr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type batchStockItem struct {
+	ProductId int64
+	Qty       float64
+	IsInStock bool
+}
+
+func TestUpdateBatchToSql(t *testing.T) {
+	s := createFakeSession()
+
+	sql, args := s.UpdateBatch("cataloginventory_stock_item", "product_id", "qty", "is_in_stock").
+		Values(1, 10.0, true).
+		Record(&batchStockItem{ProductId: 2, Qty: 0}).
+		Where("stock_id = ?", 1).
+		ToSql()
+	assert.Equal(t, "UPDATE cataloginventory_stock_item SET "+
+		"`qty` = CASE `product_id` WHEN ? THEN ? WHEN ? THEN ? ELSE `qty` END, "+
+		"`is_in_stock` = CASE `product_id` WHEN ? THEN ? WHEN ? THEN ? ELSE `is_in_stock` END "+
+		"WHERE `product_id` IN (?,?) AND (stock_id = ?)", sql)
+	assert.Equal(t, []interface{}{1, 10.0, int64(2), float64(0), 1, true, int64(2), false, 1, int64(2), 1}, args)
+
+	assert.Panics(t, func() { s.UpdateBatch("t", "id", "a").Values(1, 2, 3) })
+	assert.Panics(t, func() { s.UpdateBatch("t", "id", "a").ToSql() })
+}
+
+func TestUpdateBatchChunks(t *testing.T) {
+	s := createFakeSession()
+	b := s.UpdateBatch("t", "id", "a").Batch(2)
+	for i := 0; i < 5; i++ {
+		b.Values(i, i*10)
+	}
+	chunks := b.Chunks()
+	assert.Len(t, chunks, 3)
+	assert.Len(t, chunks[2], 1)
+	assert.Exactly(t, 4, chunks[2][0][0])
+}
+
+func TestUpdateBatchExec(t *testing.T) {
+	s, r := newStmtTestSession(10)
+	stmtDriver.lastQueries()
+
+	b := s.UpdateBatch("t", "id", "a").Batch(2)
+	for i := 0; i < 3; i++ {
+		b.Values(i, i*10)
+	}
+	affected, err := b.Exec()
+	assert.NoError(t, err)
+	assert.Exactly(t, []int64{1, 1}, affected)
+	assert.Exactly(t, 2, r.events["dbr.update.batch"])
+	// cached statements get prepared again on the connection of the transaction
+	q := stmtDriver.lastQueries()
+	if assert.Len(t, q, 4) {
+		assert.Exactly(t, "UPDATE t SET `a` = CASE `id` WHEN ? THEN ? WHEN ? THEN ? ELSE `a` END WHERE `id` IN (?,?)", q[0])
+		assert.Exactly(t, "UPDATE t SET `a` = CASE `id` WHEN ? THEN ? ELSE `a` END WHERE `id` IN (?)", q[3])
+	}
+
+	tx, err := s.Begin()
+	assert.NoError(t, err)
+	affected, err = tx.UpdateBatch("t", "id", "a").Values(1, 1).Exec()
+	assert.NoError(t, err)
+	assert.Exactly(t, []int64{1}, affected)
+	assert.NoError(t, tx.Commit())
+}
@@ -12,6 +12,7 @@ type whereFragment struct {
 	Condition   string
 	Values      []interface{}
 	EqualityMap map[string]interface{}
+	Tree        Condition
 }
 
 func newWhereFragment(whereSqlOrMap interface{}, args []interface{}) *whereFragment {
@@ -22,8 +23,10 @@ func newWhereFragment(whereSqlOrMap interface{}, args []interface{}) *whereFragm
 		return &whereFragment{EqualityMap: pred}
 	case Eq:
 		return &whereFragment{EqualityMap: map[string]interface{}(pred)}
+	case Condition:
+		return &whereFragment{Tree: pred}
 	default:
-		panic("Invalid argument passed to Where. Pass a string or an Eq map.")
+		panic("Invalid argument passed to Where. Pass a string, an Eq map or a Condition.")
 	}
 
 	return nil
@@ -45,6 +48,15 @@ func writeWhereFragmentsToSql(fragments []*whereFragment, sql *bytes.Buffer, arg
 			if len(f.Values) > 0 {
 				*args = append(*args, f.Values...)
 			}
+		} else if f.Tree != nil {
+			if anyConditions {
+				sql.WriteString(" AND (")
+			} else {
+				sql.WriteRune('(')
+				anyConditions = true
+			}
+			f.Tree.writeTo(sql, args)
+			sql.WriteRune(')')
 		} else if f.EqualityMap != nil {
 			anyConditions = writeEqualityMapToSql(f.EqualityMap, sql, args, anyConditions)
 		} else {
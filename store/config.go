@@ -30,12 +30,34 @@ const (
 	PathStoreInURL             = "web/url/use_store"
 	PathStoreRedirectToBase    = "web/url/redirect_to_base"
 	PathSecureInFrontend       = "web/secure/use_in_frontend"
+	// PathSecureOffloaderHeader contains the name of the HTTP header which
+	// an SSL offloader sets to mark a request as secure.
+	PathSecureOffloaderHeader = "web/secure/offloader_header"
+	// PathStoreAcceptLanguage enables the store view selection by the HTTP
+	// header Accept-Language. See the AcceptLanguage* constants for the values.
+	PathStoreAcceptLanguage = "web/url/accept_language"
+
+	// PathCookieLifetime lifetime of the cookies in seconds. 0 creates session cookies.
+	PathCookieLifetime = "web/cookie/cookie_lifetime"
+	PathCookiePath     = "web/cookie/cookie_path"
+	PathCookieDomain   = "web/cookie/cookie_domain"
+	// PathCookieHTTPOnly enabled by default, only 0 disables the HttpOnly flag.
+	PathCookieHTTPOnly = "web/cookie/cookie_httponly"
+	// PathCookieSecure forces the Secure flag. Cookies of secure requests are always secure.
+	PathCookieSecure = "web/cookie/cookie_secure"
+	// PathCookieSameSite one of the values lax, strict or none.
+	PathCookieSameSite = "web/cookie/cookie_samesite"
+	// PathCookieRestriction enables the cookie restriction mode. Cookies will
+	// only be set after the customer has given the consent.
+	PathCookieRestriction = "web/cookie/cookie_restriction"
+	// PathCookieRestrictionLifetime lifetime of the consent cookie in seconds.
+	PathCookieRestrictionLifetime = "web/cookie/cookie_restriction_lifetime"
 
 	PathUnsecureBaseURL = "web/unsecure/base_url"
 	PathSecureBaseURL   = "web/secure/base_url"
 
-	//	PathSecureBaseLinkUrl   = "web/secure/base_link_url"
-	//	PathUnsecureBaseLinkUrl = "web/unsecure/base_link_url"
+	PathSecureBaseLinkURL   = "web/secure/base_link_url"
+	PathUnsecureBaseLinkURL = "web/unsecure/base_link_url"
 
 	PathSecureBaseStaticURL   = "web/secure/base_static_url"
 	PathUnsecureBaseStaticURL = "web/unsecure/base_static_url"
@@ -43,6 +65,12 @@ const (
 	PathSecureBaseMediaURL   = "web/secure/base_media_url"
 	PathUnsecureBaseMediaURL = "web/unsecure/base_media_url"
 
+	// PathStaticSign enables the version segment in the URLs of static view files.
+	PathStaticSign = "dev/static/sign"
+	// PathStaticVersion contains the deployed version of the static view files.
+	// Should be set during deployment, similar to config.PathCSBaseURL.
+	PathStaticVersion = "web/corestore/static_version"
+
 	// This defines the base currency scope ("Currency Setup" > "Currency Options" > "Base Currency").
 	// can be 0 = Global or 1 = Website
 	PathPriceScope = "catalog/price/scope"
@@ -169,6 +197,136 @@ func init() {
 							BackendModel: nil,
 							SourceModel:  nil, // Magento\Config\Model\Config\Source\Web\Redirect
 						},
+
+						&config.Field{
+							// Path: `web/url/accept_language`,
+							ID:           "accept_language",
+							Label:        `Select Store View by Browser Language`,
+							Comment:      `0 = Disabled, 1 = Use the matching store view, 2 = Redirect to the matching store view. Applies only if no store has been selected via cookie or URL.`,
+							Type:         config.TypeSelect,
+							SortOrder:    30,
+							Visible:      config.VisibleYes,
+							Scope:        config.ScopePermAll,
+							Default:      AcceptLanguageDisabled,
+							BackendModel: nil,
+							SourceModel:  nil,
+						},
+					},
+				},
+
+				&config.Group{
+					ID:        "cookie",
+					Label:     `Default Cookie Settings`,
+					Comment:   ``,
+					SortOrder: 50,
+					Scope:     config.ScopePermAll,
+					Fields: config.FieldSlice{
+						&config.Field{
+							// Path: `web/cookie/cookie_lifetime`,
+							ID:           "cookie_lifetime",
+							Label:        `Cookie Lifetime`,
+							Comment:      ``,
+							Type:         config.TypeText,
+							SortOrder:    10,
+							Visible:      config.VisibleYes,
+							Scope:        config.ScopePermAll,
+							Default:      CookieDefaultLifetime,
+							BackendModel: nil, // Magento\Cookie\Model\Config\Backend\Lifetime
+							SourceModel:  nil,
+						},
+
+						&config.Field{
+							// Path: `web/cookie/cookie_path`,
+							ID:           "cookie_path",
+							Label:        `Cookie Path`,
+							Comment:      ``,
+							Type:         config.TypeText,
+							SortOrder:    20,
+							Visible:      config.VisibleYes,
+							Scope:        config.ScopePermAll,
+							Default:      nil,
+							BackendModel: nil, // Magento\Cookie\Model\Config\Backend\Path
+							SourceModel:  nil,
+						},
+
+						&config.Field{
+							// Path: `web/cookie/cookie_domain`,
+							ID:           "cookie_domain",
+							Label:        `Cookie Domain`,
+							Comment:      ``,
+							Type:         config.TypeText,
+							SortOrder:    30,
+							Visible:      config.VisibleYes,
+							Scope:        config.ScopePermAll,
+							Default:      nil,
+							BackendModel: nil, // Magento\Cookie\Model\Config\Backend\Domain
+							SourceModel:  nil,
+						},
+
+						&config.Field{
+							// Path: `web/cookie/cookie_httponly`,
+							ID:           "cookie_httponly",
+							Label:        `Use HTTP Only`,
+							Comment:      `<strong style="color:red">Warning</strong>:  Do not set to "No". User security could be compromised.`,
+							Type:         config.TypeSelect,
+							SortOrder:    40,
+							Visible:      config.VisibleYes,
+							Scope:        config.ScopePermAll,
+							Default:      true,
+							BackendModel: nil,
+							SourceModel:  nil, // Magento\Config\Model\Config\Source\Yesno
+						},
+
+						&config.Field{
+							// Path: `web/cookie/cookie_secure`,
+							ID:           "cookie_secure",
+							Label:        `Use Secure Cookies`,
+							Comment:      `Cookies of HTTPS requests are always secure.`,
+							Type:         config.TypeSelect,
+							SortOrder:    42,
+							Visible:      config.VisibleYes,
+							Scope:        config.ScopePermAll,
+							Default:      false,
+							BackendModel: nil,
+							SourceModel:  nil, // Magento\Config\Model\Config\Source\Yesno
+						},
+
+						&config.Field{
+							// Path: `web/cookie/cookie_samesite`,
+							ID:           "cookie_samesite",
+							Label:        `SameSite`,
+							Comment:      `lax, strict or none. None requires secure cookies.`,
+							Type:         config.TypeSelect,
+							SortOrder:    44,
+							Visible:      config.VisibleYes,
+							Scope:        config.ScopePermAll,
+							Default:      CookieSameSiteLax,
+							BackendModel: nil,
+							SourceModel:  nil,
+						},
+
+						&config.Field{
+							// Path: `web/cookie/cookie_restriction`,
+							ID:           "cookie_restriction",
+							Label:        `Cookie Restriction Mode`,
+							Comment:      ``,
+							Type:         config.TypeSelect,
+							SortOrder:    50,
+							Visible:      config.VisibleYes,
+							Scope:        config.NewScopePerm(config.ScopeDefaultID, config.ScopeWebsiteID),
+							Default:      false,
+							BackendModel: nil, // Magento\Cookie\Model\Config\Backend\Cookie
+							SourceModel:  nil, // Magento\Config\Model\Config\Source\Yesno
+						},
+
+						&config.Field{
+							// Path: `web/cookie/cookie_restriction_lifetime`,
+							ID:      "cookie_restriction_lifetime",
+							Type:    config.TypeHidden,
+							Visible: config.VisibleNo,
+							Scope:   config.NewScopePerm(config.ScopeDefaultID),
+							Default: CookieDefaultRestrictionLifetime,
+						},
 					},
 				},
 
@@ -345,6 +503,24 @@ func init() {
 				},
 			},
 		},
+		&config.Section{
+			ID: "dev",
+			Groups: config.GroupSlice{
+				&config.Group{
+					ID: "static",
+					Fields: config.FieldSlice{
+						&config.Field{
+							// Path: `dev/static/sign`,
+							ID:      "sign",
+							Label:   `Sign Static Files`,
+							Type:    config.TypeSelect,
+							Scope:   config.NewScopePerm(config.ScopeDefaultID),
+							Default: true,
+						},
+					},
+				},
+			},
+		},
 		&config.Section{
 			ID: "catalog",
 			Groups: config.GroupSlice{
@@ -0,0 +1,167 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CookieNameAllowSave name of the cookie which stores the consent of the
+	// customer per website. Same name and format as in Magento.
+	CookieNameAllowSave = `user_allowed_save_cookie`
+
+	// CookieDefaultLifetime default value of web/cookie/cookie_lifetime in seconds
+	CookieDefaultLifetime = 3600
+	// CookieDefaultRestrictionLifetime default value of web/cookie/cookie_restriction_lifetime in seconds
+	CookieDefaultRestrictionLifetime = 31536000
+
+	// Values for web/cookie/cookie_samesite
+	CookieSameSiteLax    = `lax`
+	CookieSameSiteStrict = `strict`
+	CookieSameSiteNone   = `none`
+)
+
+// NewCookie creates a new cookie configured by the web/cookie/* paths of the
+// store: Path falls back to Path(), MaxAge and Expires depend on the lifetime
+// where 0 creates a session cookie. The cookie is secure if web/cookie/cookie_secure
+// is enabled or the request is secure. HttpOnly is only disabled if
+// web/cookie/cookie_httponly has been explicitly set to 0. The request can be nil.
+// @todo create cookie manager to stick to the limits of http://www.ietf.org/rfc/rfc2109.txt page 15
+// @see http://browsercookielimits.squawky.net/
+func (s *Store) NewCookie(req *http.Request) *http.Cookie {
+	keks := &http.Cookie{
+		Name:     CookieName,
+		Value:    "",
+		Path:     s.ConfigString(PathCookiePath),
+		Domain:   s.ConfigString(PathCookieDomain),
+		Secure:   s.ConfigBool(PathCookieSecure) || s.IsSecureRequest(req),
+		HttpOnly: s.ConfigString(PathCookieHTTPOnly) != "0",
+	}
+	if keks.Path == "" {
+		keks.Path = s.Path()
+	}
+	if lt := s.configSeconds(PathCookieLifetime, CookieDefaultLifetime); lt > 0 {
+		keks.MaxAge = lt
+		keks.Expires = time.Now().Add(time.Duration(lt) * time.Second)
+	}
+	switch strings.ToLower(s.ConfigString(PathCookieSameSite)) {
+	case CookieSameSiteStrict:
+		keks.SameSite = http.SameSiteStrictMode
+	case CookieSameSiteNone:
+		keks.SameSite = http.SameSiteNoneMode
+		keks.Secure = true // browsers reject SameSite=None without Secure
+	default:
+		keks.SameSite = http.SameSiteLaxMode
+	}
+	return keks
+}
+
+// SetCookie adds a cookie which contains the store code. The cookie will not
+// be set if the cookie restriction mode is enabled and the customer has not
+// given the consent, see IsCookieAllowed().
+func (s *Store) SetCookie(res http.ResponseWriter, req *http.Request) {
+	if res != nil && s.IsCookieAllowed(req) {
+		keks := s.NewCookie(req)
+		keks.Value = s.Data().Code.String
+		http.SetCookie(res, keks)
+	}
+}
+
+// DeleteCookie deletes the store cookie
+func (s *Store) DeleteCookie(res http.ResponseWriter, req *http.Request) {
+	if res != nil {
+		keks := s.NewCookie(req)
+		keks.MaxAge = -1
+		keks.Expires = time.Now().AddDate(-10, 0, 0)
+		http.SetCookie(res, keks)
+	}
+}
+
+// IsCookieRestricted returns true if the cookie restriction mode in
+// web/cookie/cookie_restriction is enabled.
+func (s *Store) IsCookieRestricted() bool {
+	return s.ConfigBool(PathCookieRestriction)
+}
+
+// IsCookieAllowed returns true if the cookie restriction mode is disabled or
+// the request contains the consent cookie for the website of this store.
+func (s *Store) IsCookieAllowed(req *http.Request) bool {
+	if false == s.IsCookieRestricted() {
+		return true
+	}
+	_, ok := cookieConsent(req)[strconv.FormatInt(s.Data().WebsiteID, 10)]
+	return ok
+}
+
+// SetCookieConsent sets the consent cookie for the website of this store.
+// Already given consents for other websites will be kept. The lifetime is
+// read from web/cookie/cookie_restriction_lifetime.
+func (s *Store) SetCookieConsent(res http.ResponseWriter, req *http.Request) {
+	if res == nil {
+		return
+	}
+	ws := cookieConsent(req)
+	ws[strconv.FormatInt(s.Data().WebsiteID, 10)] = 1
+	v, _ := json.Marshal(ws) // map[string]int cannot fail
+
+	keks := s.NewCookie(req)
+	keks.Name = CookieNameAllowSave
+	keks.Value = url.QueryEscape(string(v))
+	keks.HttpOnly = false // read by JavaScript
+	lt := s.configSeconds(PathCookieRestrictionLifetime, CookieDefaultRestrictionLifetime)
+	keks.MaxAge = lt
+	keks.Expires = time.Now().Add(time.Duration(lt) * time.Second)
+	http.SetCookie(res, keks)
+}
+
+// cookieConsent decodes the consent cookie. Key is the website ID. Never nil.
+func cookieConsent(req *http.Request) map[string]int {
+	ws := make(map[string]int)
+	if req == nil {
+		return ws
+	}
+	keks, err := req.Cookie(CookieNameAllowSave)
+	if err != nil {
+		return ws
+	}
+	v, err := url.QueryUnescape(keks.Value)
+	if err != nil {
+		return ws
+	}
+	if err := json.Unmarshal([]byte(v), &ws); err != nil {
+		return make(map[string]int)
+	}
+	return ws
+}
+
+// configSeconds reads a duration in seconds. Empty or invalid values return
+// the default value.
+func (s *Store) configSeconds(path string, def int) int {
+	v := s.ConfigString(path)
+	if v == "" {
+		return def
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return def
+	}
+	return i
+}
@@ -0,0 +1,101 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreNewCookie(t *testing.T) {
+	s := newURLTestStore(map[string]string{
+		config.MockPathScopeDefault(0, store.PathUnsecureBaseURL): "http://cs.io/shop/",
+	}, nil)
+	keks := s.NewCookie(nil)
+	assert.Exactly(t, store.CookieName, keks.Name)
+	assert.Exactly(t, "/shop/", keks.Path)
+	assert.Exactly(t, "", keks.Domain)
+	assert.Exactly(t, store.CookieDefaultLifetime, keks.MaxAge)
+	assert.True(t, keks.HttpOnly)
+	assert.False(t, keks.Secure)
+	assert.Exactly(t, http.SameSiteLaxMode, keks.SameSite)
+
+	req := getTestRequest(t, "GET", "https://cs.io/", nil)
+	req.TLS = &tls.ConnectionState{}
+	assert.True(t, s.NewCookie(req).Secure)
+
+	s = newURLTestStore(map[string]string{
+		config.MockPathScopeStore(1, store.PathCookiePath):     "/de/",
+		config.MockPathScopeStore(1, store.PathCookieDomain):   ".cs.io",
+		config.MockPathScopeStore(1, store.PathCookieLifetime): "0",
+		config.MockPathScopeStore(1, store.PathCookieSameSite): "None",
+		config.MockPathScopeStore(1, store.PathCookieHTTPOnly): "0",
+	}, nil)
+	keks = s.NewCookie(nil)
+	assert.Exactly(t, "/de/", keks.Path)
+	assert.Exactly(t, ".cs.io", keks.Domain)
+	assert.Exactly(t, 0, keks.MaxAge)
+	assert.True(t, keks.Expires.IsZero())
+	assert.False(t, keks.HttpOnly)
+	assert.True(t, keks.Secure)
+	assert.Exactly(t, http.SameSiteNoneMode, keks.SameSite)
+}
+
+func TestStoreCookieRestriction(t *testing.T) {
+	s := newURLTestStore(map[string]string{
+		config.MockPathScopeStore(1, store.PathCookieRestrictionLifetime): "60",
+	}, map[string]bool{
+		config.MockPathScopeStore(1, store.PathCookieRestriction): true,
+	})
+	assert.True(t, s.IsCookieRestricted())
+
+	req := getTestRequest(t, "GET", "http://cs.io/", nil)
+	assert.False(t, s.IsCookieAllowed(req))
+	rec := httptest.NewRecorder()
+	s.SetCookie(rec, req)
+	assert.Empty(t, rec.Header().Get("Set-Cookie"))
+
+	// consent of another website and broken values are not sufficient
+	for _, v := range []string{url.QueryEscape(`{"2":1}`), "%%", "{"} {
+		req = getTestRequest(t, "GET", "http://cs.io/", &http.Cookie{Name: store.CookieNameAllowSave, Value: v})
+		assert.False(t, s.IsCookieAllowed(req), v)
+	}
+
+	rec = httptest.NewRecorder()
+	s.SetCookieConsent(rec, req)
+	res := http.Response{Header: rec.Header()}
+	cks := res.Cookies()
+	if assert.Len(t, cks, 1) {
+		assert.Exactly(t, store.CookieNameAllowSave, cks[0].Name)
+		assert.Exactly(t, 60, cks[0].MaxAge)
+		assert.False(t, cks[0].HttpOnly)
+
+		req = getTestRequest(t, "GET", "http://cs.io/", cks[0])
+		assert.True(t, s.IsCookieAllowed(req))
+		rec = httptest.NewRecorder()
+		s.SetCookie(rec, req)
+		assert.Contains(t, rec.Header().Get("Set-Cookie"), store.CookieName+"=de")
+	}
+
+	// restriction mode disabled
+	assert.True(t, newURLTestStore(nil, nil).IsCookieAllowed(nil))
+}
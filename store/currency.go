@@ -0,0 +1,182 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/juju/errgo"
+	"golang.org/x/text/language"
+)
+
+// CookieNameCurrency name of the cookie and of the JSON web token claim
+// which contain the display currency selected by the customer.
+const CookieNameCurrency = `currency`
+
+// ErrCurrencyNotAllowed the currency is not listed in currency/options/allow
+var ErrCurrencyNotAllowed = errors.New("Currency not allowed")
+
+// AllowedCurrencies returns the currency codes from currency/options/allow
+// which can be used as display currency in this store.
+func (s *Store) AllowedCurrencies() []string {
+	var ret []string
+	for _, c := range strings.Split(s.ConfigString(directory.PathCurrencyAllow), ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// IsCurrencyAllowed checks if a currency code is listed in AllowedCurrencies().
+func (s *Store) IsCurrencyAllowed(code string) bool {
+	for _, c := range s.AllowedCurrencies() {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultCurrencyCode returns the display currency from currency/options/default.
+// If the default currency is not allowed the first allowed currency will
+// be returned. Falls back to the base currency of the website.
+// @see app/code/Magento/Store/Model/Store.php::getCurrentCurrencyCode
+func (s *Store) DefaultCurrencyCode() (language.Currency, error) {
+	code := s.ConfigString(directory.PathCurrencyDefault)
+	if allowed := s.AllowedCurrencies(); len(allowed) > 0 && false == s.IsCurrencyAllowed(code) {
+		code = allowed[0]
+	}
+	if code == "" {
+		return s.Website().BaseCurrencyCode()
+	}
+	return language.ParseCurrency(code)
+}
+
+// CurrentCurrencyCode returns the display currency selected by the customer.
+// The currency will be read from the JSON web token and then from the cookie.
+// Both arguments can be nil. A selected currency must be allowed otherwise
+// DefaultCurrencyCode() will be returned.
+func (s *Store) CurrentCurrencyCode(req *http.Request, t *jwt.Token) (language.Currency, error) {
+	for _, code := range [...]string{GetCurrencyCodeFromClaim(t), GetCurrencyCodeFromCookie(req)} {
+		if code != "" && s.IsCurrencyAllowed(code) {
+			if c, err := language.ParseCurrency(code); err == nil {
+				return c, nil
+			}
+		}
+	}
+	return s.DefaultCurrencyCode()
+}
+
+// CurrentCurrency returns the display currency for the current request.
+// See CurrentCurrencyCode().
+// @see app/code/Magento/Store/Model/Store.php::getCurrentCurrency
+func (s *Store) CurrentCurrency(req *http.Request, t *jwt.Token) (directory.Currency, error) {
+	c, err := s.CurrentCurrencyCode(req, t)
+	if err != nil {
+		return directory.Currency{}, errgo.Mask(err)
+	}
+	return directory.NewCurrency(c, s.Website().rates), nil
+}
+
+// ConvertPrice converts a price from the base currency of the website into the
+// current display currency. The formatters for the locale of the store and
+// the display currency get applied if they are available, see FormatPrice().
+// A missing formatter does not fail the conversion.
+func (s *Store) ConvertPrice(price money.Currency, req *http.Request, t *jwt.Token) (money.Currency, error) {
+	base, err := s.Website().BaseCurrency()
+	if err != nil {
+		return price, errgo.Mask(err)
+	}
+	cur, err := s.CurrentCurrencyCode(req, t)
+	if err != nil {
+		return price, errgo.Mask(err)
+	}
+	if price, err = base.Convert(price, cur); err != nil {
+		return price, errgo.Mask(err)
+	}
+	if fp, err := s.FormatPrice(price, cur); err == nil {
+		price = fp
+	}
+	return price, nil
+}
+
+// FormatPrice applies the number and currency formatters for the store
+// locale and the currency cur to a price. It does not convert the price.
+func (s *Store) FormatPrice(price money.Currency, cur language.Currency) (money.Currency, error) {
+	nf, err := s.NumberFormatter()
+	if err != nil {
+		return price, err
+	}
+	cf, err := s.currencyFormatter(cur.String())
+	if err != nil {
+		return price, err
+	}
+	price.Option(money.FormatNumber(nf), money.FormatCurrency(cf))
+	return price, nil
+}
+
+// SetCurrencyCookie sets the cookie with the selected display currency. The
+// currency must be allowed. The cookie attributes are the same as for the
+// store cookie, see NewCookie() and the cookie restriction mode in SetCookie().
+func (s *Store) SetCurrencyCookie(res http.ResponseWriter, req *http.Request, code string) error {
+	if false == s.IsCurrencyAllowed(code) {
+		return ErrCurrencyNotAllowed
+	}
+	if res != nil && s.IsCookieAllowed(req) {
+		keks := s.NewCookie(req)
+		keks.Name = CookieNameCurrency
+		keks.Value = code
+		http.SetCookie(res, keks)
+	}
+	return nil
+}
+
+// AddCurrencyClaim adds the selected display currency to a JSON web token.
+// The currency must be allowed.
+func (s *Store) AddCurrencyClaim(t *jwt.Token, code string) error {
+	if false == s.IsCurrencyAllowed(code) {
+		return ErrCurrencyNotAllowed
+	}
+	t.Claims[CookieNameCurrency] = code
+	return nil
+}
+
+// GetCurrencyCodeFromClaim returns the selected display currency from a JSON
+// web token or an empty string.
+func GetCurrencyCodeFromClaim(t *jwt.Token) string {
+	if t == nil {
+		return ""
+	}
+	c, _ := t.Claims[CookieNameCurrency].(string)
+	return c
+}
+
+// GetCurrencyCodeFromCookie returns the selected display currency from the
+// currency cookie or an empty string.
+func GetCurrencyCodeFromCookie(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	if keks, err := req.Cookie(CookieNameCurrency); err == nil {
+		return keks.Value
+	}
+	return ""
+}
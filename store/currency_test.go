@@ -0,0 +1,155 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/corestoreio/csfw/storage/money"
+	"github.com/corestoreio/csfw/store"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func newCurrencyTestStore(allow, def string) *store.Store {
+	s := newURLTestStore(map[string]string{
+		config.MockPathScopeStore(1, directory.PathCurrencyAllow):   allow,
+		config.MockPathScopeStore(1, directory.PathCurrencyDefault): def,
+		config.MockPathScopeWebsite(1, directory.PathCurrencyBase):  "EUR",
+		config.MockPathScopeWebsite(1, store.PathPriceScope):        store.PriceScopeWebsite,
+		config.MockPathScopeStore(1, directory.PathDefaultLocale):   "de_CH",
+	}, nil)
+	s.Website().ApplyOptions(store.SetWebsiteCurrencyRates(
+		directory.NewRates().
+			Set(language.MustParseCurrency("EUR"), language.MustParseCurrency("CHF"), 1.1).
+			Set(language.MustParseCurrency("EUR"), language.MustParseCurrency("USD"), 1.25),
+	))
+	return s
+}
+
+func TestStoreAllowedCurrencies(t *testing.T) {
+	s := newCurrencyTestStore("EUR, CHF,,USD", "CHF")
+	assert.Exactly(t, []string{"EUR", "CHF", "USD"}, s.AllowedCurrencies())
+	assert.True(t, s.IsCurrencyAllowed("USD"))
+	assert.False(t, s.IsCurrencyAllowed("GBP"))
+
+	assert.Nil(t, newCurrencyTestStore("", "CHF").AllowedCurrencies())
+}
+
+func TestStoreDefaultCurrencyCode(t *testing.T) {
+	tests := []struct {
+		allow, def string
+		want       string
+	}{
+		{"EUR,CHF", "CHF", "CHF"},
+		{"EUR,CHF", "USD", "EUR"},
+		{"", "USD", "USD"},
+		{"", "", "EUR"},
+	}
+	for _, test := range tests {
+		c, err := newCurrencyTestStore(test.allow, test.def).DefaultCurrencyCode()
+		assert.NoError(t, err, "Test: %#v", test)
+		assert.Exactly(t, test.want, c.String(), "Test: %#v", test)
+	}
+}
+
+func TestStoreCurrentCurrency(t *testing.T) {
+	s := newCurrencyTestStore("EUR,CHF,USD", "CHF")
+
+	req, err := http.NewRequest("GET", "http://corestore.io/", nil)
+	assert.NoError(t, err)
+
+	c, err := s.CurrentCurrencyCode(nil, nil)
+	assert.NoError(t, err)
+	assert.Exactly(t, "CHF", c.String())
+
+	req.AddCookie(&http.Cookie{Name: store.CookieNameCurrency, Value: "USD"})
+	c, err = s.CurrentCurrencyCode(req, nil)
+	assert.NoError(t, err)
+	assert.Exactly(t, "USD", c.String())
+
+	token := jwt.New(jwt.SigningMethodHS256)
+	assert.NoError(t, s.AddCurrencyClaim(token, "EUR"))
+	assert.EqualError(t, s.AddCurrencyClaim(token, "GBP"), store.ErrCurrencyNotAllowed.Error())
+	cur, err := s.CurrentCurrency(req, token)
+	assert.NoError(t, err)
+	assert.Exactly(t, "EUR", cur.ISO.String())
+
+	// not allowed currencies in the cookie get ignored
+	req, err = http.NewRequest("GET", "http://corestore.io/", nil)
+	assert.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: store.CookieNameCurrency, Value: "GBP"})
+	c, err = s.CurrentCurrencyCode(req, nil)
+	assert.NoError(t, err)
+	assert.Exactly(t, "CHF", c.String())
+}
+
+func TestStoreConvertPrice(t *testing.T) {
+	s := newCurrencyTestStore("EUR,CHF,USD,GBP", "CHF")
+
+	p, err := s.ConvertPrice(money.New().Setf(100), nil, nil)
+	assert.NoError(t, err)
+	assert.Exactly(t, 110.0, p.Getf())
+	assert.Exactly(t, "CHF", string(p.Symbol()))
+
+	req, err := http.NewRequest("GET", "http://corestore.io/", nil)
+	assert.NoError(t, err)
+	req.AddCookie(&http.Cookie{Name: store.CookieNameCurrency, Value: "GBP"})
+	_, err = s.ConvertPrice(money.New().Setf(100), req, nil)
+	assert.EqualError(t, err, directory.ErrCurrencyRateNotFound.Error())
+}
+
+func TestStoreConvertPriceWithoutFormatter(t *testing.T) {
+	defer func(cf func(language.Tag, language.Currency) (i18n.CurrencyFormatter, error)) {
+		store.NewCurrencyFormatter = cf
+	}(store.NewCurrencyFormatter)
+	store.NewCurrencyFormatter = func(language.Tag, language.Currency) (i18n.CurrencyFormatter, error) {
+		return nil, errors.New("no currency formatter for de_DE")
+	}
+
+	s := newURLTestStore(map[string]string{
+		config.MockPathScopeStore(1, directory.PathCurrencyAllow):   "EUR,USD",
+		config.MockPathScopeStore(1, directory.PathCurrencyDefault): "USD",
+		config.MockPathScopeWebsite(1, directory.PathCurrencyBase):  "EUR",
+		config.MockPathScopeWebsite(1, store.PathPriceScope):        store.PriceScopeWebsite,
+		config.MockPathScopeStore(1, directory.PathDefaultLocale):   "de_DE",
+	}, nil)
+	s.Website().ApplyOptions(store.SetWebsiteCurrencyRates(
+		directory.NewRates().Set(language.MustParseCurrency("EUR"), language.MustParseCurrency("USD"), 1.25),
+	))
+	s.ResetLocale()
+
+	_, err := s.FormatPrice(money.New().Setf(100), language.MustParseCurrency("USD"))
+	assert.EqualError(t, err, "no currency formatter for de_DE")
+
+	p, err := s.ConvertPrice(money.New().Setf(100), nil, nil)
+	assert.NoError(t, err)
+	assert.Exactly(t, 125.0, p.Getf())
+}
+
+func TestStoreSetCurrencyCookie(t *testing.T) {
+	s := newCurrencyTestStore("EUR,CHF", "CHF")
+	rec := httptest.NewRecorder()
+	assert.NoError(t, s.SetCurrencyCookie(rec, nil, "EUR"))
+	assert.Contains(t, rec.Header().Get("Set-Cookie"), store.CookieNameCurrency+"=EUR")
+	assert.EqualError(t, s.SetCurrencyCookie(rec, nil, "USD"), store.ErrCurrencyNotAllowed.Error())
+}
@@ -222,7 +222,7 @@ func (s TableGroupSlice) Len() int { return len(s) }
 // FindByID returns a TableGroup if found by id or an error
 func (s TableGroupSlice) FindByID(id int64) (*TableGroup, error) {
 	for _, g := range s {
-		if g.GroupID == id {
+		if g != nil && g.GroupID == id {
 			return g, nil
 		}
 	}
@@ -0,0 +1,270 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/corestoreio/csfw/config"
+)
+
+const (
+	// IntegrityOrphanGroup a group points to a website which does not exist.
+	IntegrityOrphanGroup IntegrityKind = iota + 1
+	// IntegrityOrphanStore a store points to a website or group which does not exist.
+	IntegrityOrphanStore
+	// IntegrityStoreWebsiteMismatch a store and its group are assigned to different websites.
+	IntegrityStoreWebsiteMismatch
+	// IntegrityDefaultGroupNotFound the default_group_id of a website does not exist.
+	IntegrityDefaultGroupNotFound
+	// IntegrityDefaultGroupForeign the default_group_id of a website points to a group of another website.
+	IntegrityDefaultGroupForeign
+	// IntegrityDefaultStoreNotFound the default_store_id of a group does not exist.
+	IntegrityDefaultStoreNotFound
+	// IntegrityDefaultStoreForeign the default_store_id of a group points to a store of another group.
+	IntegrityDefaultStoreForeign
+	// IntegrityDefaultStoreInactive the default store of a group is not active.
+	IntegrityDefaultStoreInactive
+	// IntegrityDefaultWebsiteNone no website has been marked as default. The
+	// violation has the scope config.ScopeDefaultID and no ID.
+	IntegrityDefaultWebsiteNone
+	// IntegrityDefaultWebsiteMultiple more than one website has been marked as
+	// default. Each of those websites gets its own violation.
+	IntegrityDefaultWebsiteMultiple
+	// IntegrityDuplicateCode a website code or a store code is used more than once.
+	IntegrityDuplicateCode
+	// IntegrityInvalidCode a website code or a store code does not pass ValidateStoreCode().
+	IntegrityInvalidCode
+)
+
+type (
+	// IntegrityKind defines the type of a violation found by ValidateIntegrity().
+	IntegrityKind uint8
+
+	// IntegrityViolation describes one broken relation between a website, group or store.
+	IntegrityViolation struct {
+		Kind IntegrityKind
+		// Scope is one of config.ScopeWebsiteID, config.ScopeGroupID or
+		// config.ScopeStoreID and defines to which table ID and Code belong.
+		Scope config.ScopeGroup
+		ID    int64
+		// Code is empty for groups
+		Code    string
+		Message string
+	}
+
+	// IntegrityReport contains all violations found by ValidateIntegrity(). An empty
+	// report means that the websites, groups and stores can be safely used to
+	// create the Website, Group and Store types without running into a panic.
+	// IntegrityReport implements the error interface.
+	IntegrityReport struct {
+		Violations []IntegrityViolation
+	}
+)
+
+const integrityKindName = "OrphanGroupOrphanStoreStoreWebsiteMismatchDefaultGroupNotFoundDefaultGroupForeignDefaultStoreNotFoundDefaultStoreForeignDefaultStoreInactiveDefaultWebsiteNoneDefaultWebsiteMultipleDuplicateCodeInvalidCode"
+
+var integrityKindIndex = [...]uint8{0, 11, 22, 42, 62, 81, 101, 120, 140, 158, 180, 193, 204}
+
+// String human readable name of an IntegrityKind
+func (i IntegrityKind) String() string {
+	if i == 0 || int(i) >= len(integrityKindIndex) {
+		return fmt.Sprintf("IntegrityKind(%d)", i)
+	}
+	return integrityKindName[integrityKindIndex[i-1]:integrityKindIndex[i]]
+}
+
+// Hard returns true if the violation leads to panics when creating the
+// Website, Group and Store types or when looking up the default store view:
+// missing parents, missing default groups or stores and zero or multiple
+// default websites.
+func (i IntegrityKind) Hard() bool {
+	switch i {
+	case IntegrityOrphanGroup, IntegrityOrphanStore, IntegrityStoreWebsiteMismatch,
+		IntegrityDefaultGroupNotFound, IntegrityDefaultStoreNotFound,
+		IntegrityDefaultWebsiteNone, IntegrityDefaultWebsiteMultiple:
+		return true
+	}
+	return false
+}
+
+// String returns a one line description of the violation
+func (v IntegrityViolation) String() string {
+	if v.Scope == config.ScopeDefaultID {
+		return fmt.Sprintf("%s: %s: %s", v.Kind, v.Scope, v.Message)
+	}
+	if v.Code != "" {
+		return fmt.Sprintf("%s: %s ID %d Code %q: %s", v.Kind, v.Scope, v.ID, v.Code, v.Message)
+	}
+	return fmt.Sprintf("%s: %s ID %d: %s", v.Kind, v.Scope, v.ID, v.Message)
+}
+
+// OK returns true if no violations have been found.
+func (r *IntegrityReport) OK() bool {
+	return r == nil || len(r.Violations) == 0
+}
+
+// Len returns the number of violations
+func (r *IntegrityReport) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.Violations)
+}
+
+// Hard returns a new report containing only the hard violations, see
+// IntegrityKind.Hard().
+func (r *IntegrityReport) Hard() *IntegrityReport {
+	h := &IntegrityReport{}
+	if r == nil {
+		return h
+	}
+	for _, v := range r.Violations {
+		if v.Kind.Hard() {
+			h.Violations = append(h.Violations, v)
+		}
+	}
+	return h
+}
+
+// Filter returns all violations of a specific kind
+func (r *IntegrityReport) Filter(k IntegrityKind) []IntegrityViolation {
+	if r == nil {
+		return nil
+	}
+	var vs []IntegrityViolation
+	for _, v := range r.Violations {
+		if v.Kind == k {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// Error satisfies the error interface and lists all violations line by line.
+func (r *IntegrityReport) Error() string {
+	if r.OK() {
+		return ""
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Store integrity check failed with %d violation(s):", r.Len())
+	for _, v := range r.Violations {
+		buf.WriteString("\n")
+		buf.WriteString(v.String())
+	}
+	return buf.String()
+}
+
+func (r *IntegrityReport) add(k IntegrityKind, sg config.ScopeGroup, id int64, code, format string, args ...interface{}) {
+	r.Violations = append(r.Violations, IntegrityViolation{
+		Kind:    k,
+		Scope:   sg,
+		ID:      id,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidateIntegrity checks the relations between websites, groups and stores.
+// It detects orphan groups and stores, default group and default store IDs which
+// point to missing or foreign rows, inactive default stores, zero or multiple
+// default websites, duplicate codes and invalid codes. The returned report is
+// never nil. Nil entries in the slices will be skipped.
+func ValidateIntegrity(tws TableWebsiteSlice, tgs TableGroupSlice, tss TableStoreSlice) *IntegrityReport {
+	r := &IntegrityReport{}
+
+	var defaultWebsites TableWebsiteSlice
+	websiteCodes := make(map[string]int64, len(tws))
+	for _, w := range tws {
+		if w == nil {
+			continue
+		}
+		if w.IsDefault.Valid && w.IsDefault.Bool {
+			defaultWebsites = append(defaultWebsites, w)
+		}
+		checkCode(r, config.ScopeWebsiteID, w.WebsiteID, w.Code.String, websiteCodes)
+
+		g, err := tgs.FindByID(w.DefaultGroupID)
+		switch {
+		case err != nil:
+			r.add(IntegrityDefaultGroupNotFound, config.ScopeWebsiteID, w.WebsiteID, w.Code.String, "Default group ID %d not found", w.DefaultGroupID)
+		case g.WebsiteID != w.WebsiteID:
+			r.add(IntegrityDefaultGroupForeign, config.ScopeWebsiteID, w.WebsiteID, w.Code.String, "Default group ID %d belongs to website ID %d", g.GroupID, g.WebsiteID)
+		}
+	}
+
+	switch {
+	case len(defaultWebsites) == 0 && len(tws) > 0:
+		r.add(IntegrityDefaultWebsiteNone, config.ScopeDefaultID, 0, "", "No website has been marked as default")
+	case len(defaultWebsites) > 1:
+		for _, w := range defaultWebsites {
+			r.add(IntegrityDefaultWebsiteMultiple, config.ScopeWebsiteID, w.WebsiteID, w.Code.String, "One of %d websites marked as default", len(defaultWebsites))
+		}
+	}
+
+	for _, g := range tgs {
+		if g == nil {
+			continue
+		}
+		if _, err := tws.FindByID(g.WebsiteID); err != nil {
+			r.add(IntegrityOrphanGroup, config.ScopeGroupID, g.GroupID, "", "Website ID %d not found", g.WebsiteID)
+		}
+
+		s, err := tss.FindByID(g.DefaultStoreID)
+		switch {
+		case err != nil:
+			r.add(IntegrityDefaultStoreNotFound, config.ScopeGroupID, g.GroupID, "", "Default store ID %d not found", g.DefaultStoreID)
+		case s.GroupID != g.GroupID:
+			r.add(IntegrityDefaultStoreForeign, config.ScopeGroupID, g.GroupID, "", "Default store ID %d belongs to group ID %d", s.StoreID, s.GroupID)
+		case false == s.IsActive:
+			r.add(IntegrityDefaultStoreInactive, config.ScopeGroupID, g.GroupID, "", "Default store ID %d is not active", s.StoreID)
+		}
+	}
+
+	storeCodes := make(map[string]int64, len(tss))
+	for _, s := range tss {
+		if s == nil {
+			continue
+		}
+		checkCode(r, config.ScopeStoreID, s.StoreID, s.Code.String, storeCodes)
+
+		if _, err := tws.FindByID(s.WebsiteID); err != nil {
+			r.add(IntegrityOrphanStore, config.ScopeStoreID, s.StoreID, s.Code.String, "Website ID %d not found", s.WebsiteID)
+		}
+		g, err := tgs.FindByID(s.GroupID)
+		switch {
+		case err != nil:
+			r.add(IntegrityOrphanStore, config.ScopeStoreID, s.StoreID, s.Code.String, "Group ID %d not found", s.GroupID)
+		case g.WebsiteID != s.WebsiteID:
+			r.add(IntegrityStoreWebsiteMismatch, config.ScopeStoreID, s.StoreID, s.Code.String, "Website ID %d differs from website ID %d of group ID %d", s.WebsiteID, g.WebsiteID, g.GroupID)
+		}
+	}
+	return r
+}
+
+// checkCode validates a website or store code and detects duplicates by using
+// the map seen which contains the already processed codes.
+func checkCode(r *IntegrityReport, sg config.ScopeGroup, id int64, code string, seen map[string]int64) {
+	if err := ValidateStoreCode(code); err != nil {
+		r.add(IntegrityInvalidCode, sg, id, code, "%s", err)
+		return
+	}
+	if prevID, ok := seen[code]; ok {
+		r.add(IntegrityDuplicateCode, sg, id, code, "Code already used by ID %d", prevID)
+		return
+	}
+	seen[code] = id
+}
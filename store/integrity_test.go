@@ -0,0 +1,161 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func nullString(s string) dbr.NullString {
+	return dbr.NullString{NullString: sql.NullString{String: s, Valid: true}}
+}
+
+func TestStorageIntegrityOK(t *testing.T) {
+	r := testStorage.Integrity()
+	assert.True(t, r.OK(), r.Error())
+	assert.Exactly(t, 0, r.Len())
+	assert.Exactly(t, "", r.Error())
+}
+
+func TestValidateIntegrity(t *testing.T) {
+	tws := store.TableWebsiteSlice{
+		&store.TableWebsite{WebsiteID: 0, Code: nullString("admin"), DefaultGroupID: 0},
+		&store.TableWebsite{WebsiteID: 1, Code: nullString("euro"), DefaultGroupID: 9},
+		&store.TableWebsite{WebsiteID: 2, Code: nullString("euro"), DefaultGroupID: 1},
+		&store.TableWebsite{WebsiteID: 3, Code: nullString("1nvalid"), DefaultGroupID: 4},
+		nil,
+	}
+	tgs := store.TableGroupSlice{
+		&store.TableGroup{GroupID: 0, WebsiteID: 0, DefaultStoreID: 0},
+		&store.TableGroup{GroupID: 1, WebsiteID: 1, DefaultStoreID: 2},
+		&store.TableGroup{GroupID: 2, WebsiteID: 1, DefaultStoreID: 1},
+		&store.TableGroup{GroupID: 3, WebsiteID: 7, DefaultStoreID: 99},
+		&store.TableGroup{GroupID: 4, WebsiteID: 3, DefaultStoreID: 4},
+	}
+	tss := store.TableStoreSlice{
+		&store.TableStore{StoreID: 0, Code: nullString("admin"), WebsiteID: 0, GroupID: 0, IsActive: true},
+		&store.TableStore{StoreID: 1, Code: nullString("de"), WebsiteID: 1, GroupID: 1, IsActive: true},
+		&store.TableStore{StoreID: 2, Code: nullString("de"), WebsiteID: 1, GroupID: 1, IsActive: false},
+		&store.TableStore{StoreID: 3, Code: nullString("uk"), WebsiteID: 8, GroupID: 8, IsActive: true},
+		&store.TableStore{StoreID: 4, Code: nullString("ch"), WebsiteID: 1, GroupID: 4, IsActive: true},
+	}
+
+	r := store.ValidateIntegrity(tws, tgs, tss)
+	assert.False(t, r.OK())
+	assert.Contains(t, r.Error(), "Store integrity check failed with")
+
+	tests := []struct {
+		kind      store.IntegrityKind
+		wantScope config.ScopeGroup
+		wantID    int64
+	}{
+		{store.IntegrityDefaultGroupNotFound, config.ScopeWebsiteID, 1},
+		{store.IntegrityDefaultGroupForeign, config.ScopeWebsiteID, 2},
+		{store.IntegrityDefaultWebsiteNone, config.ScopeDefaultID, 0},
+		{store.IntegrityDuplicateCode, config.ScopeWebsiteID, 2},
+		{store.IntegrityDuplicateCode, config.ScopeStoreID, 2},
+		{store.IntegrityInvalidCode, config.ScopeWebsiteID, 3},
+		{store.IntegrityOrphanGroup, config.ScopeGroupID, 3},
+		{store.IntegrityDefaultStoreNotFound, config.ScopeGroupID, 3},
+		{store.IntegrityDefaultStoreForeign, config.ScopeGroupID, 2},
+		{store.IntegrityDefaultStoreInactive, config.ScopeGroupID, 1},
+		{store.IntegrityOrphanStore, config.ScopeStoreID, 3}, // website not found
+		{store.IntegrityOrphanStore, config.ScopeStoreID, 3}, // group not found
+		{store.IntegrityStoreWebsiteMismatch, config.ScopeStoreID, 4},
+	}
+	assert.Exactly(t, len(tests), r.Len(), r.Error())
+
+	found := make(map[store.IntegrityViolation]int)
+	for _, v := range r.Violations {
+		v.Message = ""
+		found[v]++
+	}
+	for _, test := range tests {
+		var code string
+		for _, v := range r.Filter(test.kind) {
+			if v.Scope == test.wantScope && v.ID == test.wantID {
+				code = v.Code
+			}
+		}
+		key := store.IntegrityViolation{Kind: test.kind, Scope: test.wantScope, ID: test.wantID, Code: code}
+		assert.True(t, found[key] > 0, "Missing violation %s", key)
+		found[key]--
+	}
+}
+
+func TestValidateIntegrityMultipleDefaultWebsites(t *testing.T) {
+	tws := store.TableWebsiteSlice{
+		&store.TableWebsite{WebsiteID: 1, Code: nullString("euro"), DefaultGroupID: 1, IsDefault: dbr.NullBool{NullBool: sql.NullBool{Bool: true, Valid: true}}},
+		&store.TableWebsite{WebsiteID: 2, Code: nullString("oz"), DefaultGroupID: 2, IsDefault: dbr.NullBool{NullBool: sql.NullBool{Bool: true, Valid: true}}},
+	}
+	tgs := store.TableGroupSlice{
+		&store.TableGroup{GroupID: 1, WebsiteID: 1, DefaultStoreID: 1},
+		&store.TableGroup{GroupID: 2, WebsiteID: 2, DefaultStoreID: 2},
+	}
+	tss := store.TableStoreSlice{
+		&store.TableStore{StoreID: 1, Code: nullString("de"), WebsiteID: 1, GroupID: 1, IsActive: true},
+		&store.TableStore{StoreID: 2, Code: nullString("au"), WebsiteID: 2, GroupID: 2, IsActive: true},
+	}
+	r := store.ValidateIntegrity(tws, tgs, tss)
+	assert.Exactly(t, 2, r.Len(), r.Error())
+	assert.Len(t, r.Filter(store.IntegrityDefaultWebsiteMultiple), 2)
+	assert.Exactly(t, `DefaultWebsiteMultiple: ScopeWebsite ID 1 Code "euro": One of 2 websites marked as default`, r.Violations[0].String())
+	assert.Exactly(t, `DefaultWebsiteMultiple: ScopeWebsite ID 2 Code "oz": One of 2 websites marked as default`, r.Violations[1].String())
+
+	tws[0].IsDefault.Bool = false
+	tws[1].IsDefault.Bool = false
+	r = store.ValidateIntegrity(tws, tgs, tss)
+	assert.Exactly(t, 1, r.Len(), r.Error())
+	assert.Exactly(t, "DefaultWebsiteNone: ScopeDefault: No website has been marked as default", r.Violations[0].String())
+}
+
+func TestIntegrityKindString(t *testing.T) {
+	assert.Exactly(t, "OrphanGroup", store.IntegrityOrphanGroup.String())
+	assert.Exactly(t, "DefaultStoreInactive", store.IntegrityDefaultStoreInactive.String())
+	assert.Exactly(t, "InvalidCode", store.IntegrityInvalidCode.String())
+	assert.Exactly(t, "IntegrityKind(0)", store.IntegrityKind(0).String())
+	assert.Exactly(t, "IntegrityKind(200)", store.IntegrityKind(200).String())
+}
+
+func TestIntegrityReportHard(t *testing.T) {
+	tws := store.TableWebsiteSlice{
+		&store.TableWebsite{WebsiteID: 1, Code: nullString("euro"), DefaultGroupID: 1, IsDefault: dbr.NullBool{NullBool: sql.NullBool{Bool: true, Valid: true}}},
+	}
+	tgs := store.TableGroupSlice{
+		&store.TableGroup{GroupID: 1, WebsiteID: 1, DefaultStoreID: 1},
+		&store.TableGroup{GroupID: 2, WebsiteID: 5, DefaultStoreID: 2},
+	}
+	tss := store.TableStoreSlice{
+		&store.TableStore{StoreID: 1, Code: nullString("de"), WebsiteID: 1, GroupID: 1, IsActive: false},
+		&store.TableStore{StoreID: 2, Code: nullString("de"), WebsiteID: 1, GroupID: 1, IsActive: true},
+	}
+	r := store.ValidateIntegrity(tws, tgs, tss)
+	assert.Exactly(t, 4, r.Len(), r.Error())
+
+	h := r.Hard()
+	assert.Exactly(t, 1, h.Len(), h.Error())
+	assert.Exactly(t, store.IntegrityOrphanGroup, h.Violations[0].Kind)
+	assert.True(t, (*store.IntegrityReport)(nil).Hard().OK())
+
+	assert.True(t, store.IntegrityOrphanStore.Hard())
+	assert.False(t, store.IntegrityDuplicateCode.Hard())
+	assert.False(t, store.IntegrityDefaultStoreInactive.Hard())
+}
@@ -0,0 +1,79 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package integritycheck loads all websites, groups and stores from the database
+// and checks their integrity. Meant to be run in a deployment pipeline.
+//
+// The database is taken from the environment variable CS_DSN. Exit codes:
+//
+//	0 no violations found
+//	1 violations found, each one gets printed on its own line to stdout
+//	2 database or loading error
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/store"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run returns the exit code so that the deferred Close gets executed before
+// main calls os.Exit.
+func run() int {
+	db, dbrConn, err := csdb.Connect()
+	if err != nil {
+		log.Printf("Connect: %s", err)
+		return 2
+	}
+	defer db.Close()
+	for _, r := range dbrConn.Replicas() {
+		defer r.Close()
+	}
+	sess := dbrConn.NewSession(nil)
+
+	var (
+		tws store.TableWebsiteSlice
+		tgs store.TableGroupSlice
+		tss store.TableStoreSlice
+	)
+	if _, err := tws.Load(sess); err != nil {
+		log.Printf("Load websites: %s", err)
+		return 2
+	}
+	if _, err := tgs.Load(sess); err != nil {
+		log.Printf("Load groups: %s", err)
+		return 2
+	}
+	if _, err := tss.Load(sess); err != nil {
+		log.Printf("Load stores: %s", err)
+		return 2
+	}
+
+	r := store.ValidateIntegrity(tws, tgs, tss)
+	if r.OK() {
+		fmt.Printf("OK: %d websites, %d groups, %d stores\n", tws.Len(), tgs.Len(), tss.Len())
+		return 0
+	}
+	for _, v := range r.Violations {
+		fmt.Println(v)
+	}
+	return 1
+}
@@ -0,0 +1,395 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/juju/errgo"
+)
+
+const (
+	// HTTPHeaderAuthorization contains the JSON web token with the prefix Bearer.
+	HTTPHeaderAuthorization = "Authorization"
+
+	// ClaimExpire expiration time as unix timestamp
+	ClaimExpire = "exp"
+	// ClaimNotBefore token must not be accepted before this unix timestamp
+	ClaimNotBefore = "nbf"
+	// ClaimIssuedAt creation time as unix timestamp
+	ClaimIssuedAt = "iat"
+	// ClaimID unique token ID used for the blacklist
+	ClaimID = "jti"
+
+	// JWTHeaderKeyID name of the token header which contains the ID of the signing key.
+	JWTHeaderKeyID = "kid"
+
+	// JWTDefaultExpire default lifetime of a token
+	JWTDefaultExpire = time.Hour
+)
+
+var (
+	ErrJWTTokenMissing      = errors.New("JSON web token not found in request")
+	ErrJWTTokenInvalid      = errors.New("JSON web token is invalid")
+	ErrJWTTokenExpired      = errors.New("JSON web token has expired")
+	ErrJWTTokenNotValidYet  = errors.New("JSON web token is not valid yet")
+	ErrJWTTokenBlacklisted  = errors.New("JSON web token has been revoked")
+	ErrJWTKeyNotFound       = errors.New("JSON web token signing key not found")
+	ErrJWTKeyMethodMismatch = errors.New("JSON web token signing method does not match the key")
+	ErrJWTNoSigningKey      = errors.New("JSON web token service has no signing key")
+	ErrJWTNoBlacklist       = errors.New("JSON web token service has no blacklist")
+)
+
+type (
+	// JWTKey contains the keys to sign and verify a token. For HMAC both keys
+	// are the same []byte secret. For RSA and ECDSA the private key can be nil
+	// if the key is only used for verification, e.g. after a key rotation.
+	JWTKey struct {
+		// ID will be set in the token header kid.
+		ID      string
+		Method  jwt.SigningMethod
+		Private interface{}
+		Public  interface{}
+	}
+
+	// Blacklister stores the IDs of revoked tokens. Implementations must be
+	// safe for concurrent use.
+	Blacklister interface {
+		// Set adds a token ID. The entry can be removed after the duration
+		// expires because then the token itself has expired.
+		Set(id string, expires time.Duration) error
+		// Has checks if a token ID has been revoked.
+		Has(id string) bool
+	}
+
+	// BlacklistMemory is the in-memory default Blacklister.
+	BlacklistMemory struct {
+		mu  sync.RWMutex
+		ids map[string]time.Time
+	}
+
+	// JWTService issues and verifies JSON web tokens containing the store code
+	// and initializes the store of a request via Manager.InitByToken().
+	JWTService struct {
+		m *Manager
+		// Expire lifetime of a new token. Default JWTDefaultExpire.
+		Expire time.Duration
+		// Blacklist contains the revoked tokens. Default BlacklistMemory.
+		Blacklist Blacklister
+
+		mu sync.RWMutex
+		// keys map key is the key ID
+		keys map[string]JWTKey
+		// signKey ID of the key used to sign new tokens
+		signKey string
+	}
+
+	// JWTOption option func for NewJWTService()
+	JWTOption func(*JWTService)
+)
+
+var _ Blacklister = (*BlacklistMemory)(nil)
+
+// NewJWTKeyHMAC creates a HMAC SHA256 key from a secret.
+func NewJWTKeyHMAC(id string, secret []byte) JWTKey {
+	return JWTKey{ID: id, Method: jwt.SigningMethodHS256, Private: secret, Public: secret}
+}
+
+// NewJWTKeyRSA creates a RSA SHA256 key from a private key.
+func NewJWTKeyRSA(id string, pk *rsa.PrivateKey) JWTKey {
+	return JWTKey{ID: id, Method: jwt.SigningMethodRS256, Private: pk, Public: &pk.PublicKey}
+}
+
+// NewJWTKeyECDSA creates an ECDSA key from a private key. The signing method
+// depends on the curve: P-256 uses ES256, P-384 ES384 and P-521 ES512.
+func NewJWTKeyECDSA(id string, pk *ecdsa.PrivateKey) JWTKey {
+	var m jwt.SigningMethod
+	switch pk.Curve.Params().BitSize {
+	case 384:
+		m = jwt.SigningMethodES384
+	case 521:
+		m = jwt.SigningMethodES512
+	default:
+		m = jwt.SigningMethodES256
+	}
+	return JWTKey{ID: id, Method: m, Private: pk, Public: &pk.PublicKey}
+}
+
+// SetJWTExpire sets the lifetime of new tokens.
+func SetJWTExpire(d time.Duration) JWTOption {
+	return func(s *JWTService) { s.Expire = d }
+}
+
+// SetJWTBlacklist sets a custom blacklist, e.g. a distributed cache.
+func SetJWTBlacklist(bl Blacklister) JWTOption {
+	return func(s *JWTService) { s.Blacklist = bl }
+}
+
+// SetJWTKeys adds the keys for verification. The first key signs new tokens.
+func SetJWTKeys(keys ...JWTKey) JWTOption {
+	return func(s *JWTService) {
+		for i := len(keys) - 1; i >= 0; i-- {
+			s.AddKey(keys[i], i == 0)
+		}
+	}
+}
+
+// NewJWTService creates a new token service for a Manager. Panics if the
+// Manager is nil.
+func NewJWTService(m *Manager, opts ...JWTOption) *JWTService {
+	if m == nil {
+		panic(ErrStoreNewArgNil)
+	}
+	s := &JWTService{
+		m:         m,
+		Expire:    JWTDefaultExpire,
+		Blacklist: NewBlacklistMemory(),
+		keys:      make(map[string]JWTKey),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	return s
+}
+
+// AddKey adds a key. If sign is true the key will be used for new tokens.
+// To rotate keys add the new key with sign true and remove the old key with
+// RemoveKey() after the lifetime of the old tokens has passed.
+func (s *JWTService) AddKey(k JWTKey, sign bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[k.ID] = k
+	if sign || s.signKey == "" {
+		s.signKey = k.ID
+	}
+}
+
+// RemoveKey removes a key. Tokens signed with that key become invalid.
+func (s *JWTService) RemoveKey(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, id)
+	if s.signKey == id {
+		s.signKey = ""
+	}
+}
+
+// NewToken creates a signed token which contains the store code and the
+// optional claims, e.g. a customer or session ID. The claims exp, nbf,
+// iat and jti will be set automatically.
+func (s *JWTService) NewToken(st *Store, claims map[string]interface{}) (string, error) {
+	s.mu.RLock()
+	k, ok := s.keys[s.signKey]
+	s.mu.RUnlock()
+	if !ok || k.Private == nil {
+		return "", ErrJWTNoSigningKey
+	}
+
+	id, err := newJWTID()
+	if err != nil {
+		return "", errgo.Mask(err)
+	}
+
+	t := jwt.New(k.Method)
+	t.Header[JWTHeaderKeyID] = k.ID
+	for key, v := range claims {
+		t.Claims[key] = v
+	}
+	if st != nil {
+		st.AddClaim(t)
+	}
+	now := time.Now()
+	t.Claims[ClaimExpire] = now.Add(s.Expire).Unix()
+	t.Claims[ClaimNotBefore] = now.Unix()
+	t.Claims[ClaimIssuedAt] = now.Unix()
+	t.Claims[ClaimID] = id
+
+	ts, err := t.SignedString(k.Private)
+	return ts, errgo.Mask(err)
+}
+
+// Parse verifies a token string. It checks the signature with the key
+// referenced in the kid header, the claims exp and nbf and the blacklist.
+// Tokens without the claims exp and jti are invalid because they would
+// never expire and could not be revoked.
+func (s *JWTService) Parse(token string) (*jwt.Token, error) {
+	var keyErr error
+	t, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		var k interface{}
+		k, keyErr = s.keyFunc(t)
+		return k, keyErr
+	})
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	if err != nil {
+		if ve, ok := err.(*jwt.ValidationError); ok {
+			switch {
+			case ve.Errors&jwt.ValidationErrorExpired != 0:
+				return nil, ErrJWTTokenExpired
+			case ve.Errors&jwt.ValidationErrorNotValidYet != 0:
+				return nil, ErrJWTTokenNotValidYet
+			}
+		}
+		return nil, ErrJWTTokenInvalid
+	}
+	if !t.Valid {
+		return nil, ErrJWTTokenInvalid
+	}
+	if _, ok := t.Claims[ClaimExpire]; !ok {
+		return nil, ErrJWTTokenInvalid
+	}
+	id, ok := t.Claims[ClaimID].(string)
+	if !ok || id == "" {
+		return nil, ErrJWTTokenInvalid
+	}
+	if s.Blacklist != nil && s.Blacklist.Has(id) {
+		return nil, ErrJWTTokenBlacklisted
+	}
+	return t, nil
+}
+
+// ParseFromRequest verifies the token from the Authorization header which
+// must have the format: Bearer <token>
+func (s *JWTService) ParseFromRequest(req *http.Request) (*jwt.Token, error) {
+	if req == nil {
+		return nil, ErrJWTTokenMissing
+	}
+	ah := req.Header.Get(HTTPHeaderAuthorization)
+	if len(ah) < 8 || false == strings.EqualFold(ah[:7], "bearer ") {
+		return nil, ErrJWTTokenMissing
+	}
+	return s.Parse(strings.TrimSpace(ah[7:]))
+}
+
+// Revoke adds the token ID to the blacklist until the token expires. The
+// expiration can be a parsed JSON number or an int64 of a token created by
+// NewToken(). Tokens without an expiration return ErrJWTTokenInvalid.
+func (s *JWTService) Revoke(t *jwt.Token) error {
+	if s.Blacklist == nil {
+		return ErrJWTNoBlacklist
+	}
+	id, ok := t.Claims[ClaimID].(string)
+	if !ok || id == "" {
+		return ErrJWTTokenInvalid
+	}
+	var exp int64
+	switch v := t.Claims[ClaimExpire].(type) {
+	case float64:
+		exp = int64(v)
+	case int64:
+		exp = v
+	case int:
+		exp = int64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return ErrJWTTokenInvalid
+		}
+		exp = int64(f)
+	default:
+		return ErrJWTTokenInvalid
+	}
+	d := time.Unix(exp, 0).Sub(time.Now())
+	if d <= 0 {
+		return nil // already expired
+	}
+	return s.Blacklist.Set(id, d)
+}
+
+// InitByRequest verifies the token from the Authorization header and
+// returns the Store from its claim. See Manager.InitByToken(). The returned
+// Store can be nil if the token does not contain a store code.
+func (s *JWTService) InitByRequest(req *http.Request, scopeType config.ScopeGroup) (*Store, *jwt.Token, error) {
+	t, err := s.ParseFromRequest(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	st, err := s.m.InitByToken(t, scopeType)
+	if err != nil {
+		return nil, t, errgo.Mask(err)
+	}
+	return st, t, nil
+}
+
+// keyFunc selects the verification key by the kid header and prevents
+// the algorithm switching attack.
+func (s *JWTService) keyFunc(t *jwt.Token) (interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	kid, _ := t.Header[JWTHeaderKeyID].(string)
+	if kid == "" {
+		kid = s.signKey
+	}
+	k, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrJWTKeyNotFound
+	}
+	if t.Method == nil || t.Method.Alg() != k.Method.Alg() {
+		return nil, ErrJWTKeyMethodMismatch
+	}
+	return k.Public, nil
+}
+
+func newJWTID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// NewBlacklistMemory creates a new in-memory blacklist. Expired entries get
+// purged when new entries are added.
+func NewBlacklistMemory() *BlacklistMemory {
+	return &BlacklistMemory{
+		ids: make(map[string]time.Time),
+	}
+}
+
+// Set adds a token ID.
+func (bl *BlacklistMemory) Set(id string, expires time.Duration) error {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	now := time.Now()
+	for k, exp := range bl.ids {
+		if now.After(exp) {
+			delete(bl.ids, k)
+		}
+	}
+	bl.ids[id] = now.Add(expires)
+	return nil
+}
+
+// Has checks if a token ID has been revoked and is not yet expired.
+func (bl *BlacklistMemory) Has(id string) bool {
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+	exp, ok := bl.ids[id]
+	return ok && time.Now().Before(exp)
+}
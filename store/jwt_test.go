@@ -0,0 +1,200 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/store"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTServiceSignVerify(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	ek, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	keys := []store.JWTKey{
+		store.NewJWTKeyHMAC("hmac", []byte(`s3cr3t`)),
+		store.NewJWTKeyRSA("rsa", rk),
+		store.NewJWTKeyECDSA("ecdsa", ek),
+	}
+	st := newURLTestStore(nil, nil)
+	for _, k := range keys {
+		js := store.NewJWTService(storeManagerRequestStore, store.SetJWTKeys(k))
+		ts, err := js.NewToken(st, map[string]interface{}{"customer": "1234"})
+		assert.NoError(t, err, k.ID)
+
+		tk, err := js.Parse(ts)
+		assert.NoError(t, err, k.ID)
+		assert.Exactly(t, k.ID, tk.Header[store.JWTHeaderKeyID])
+		assert.Exactly(t, k.Method.Alg(), tk.Method.Alg())
+		assert.Exactly(t, "de", tk.Claims[store.CookieName])
+		assert.Exactly(t, "1234", tk.Claims["customer"])
+		assert.NotEmpty(t, tk.Claims[store.ClaimID])
+		assert.Exactly(t, config.ScopeCode("de"), store.GetCodeFromClaim(tk))
+	}
+	assert.Exactly(t, "ES384", keys[2].Method.Alg())
+
+	_, err = store.NewJWTService(storeManagerRequestStore).NewToken(st, nil)
+	assert.EqualError(t, err, store.ErrJWTNoSigningKey.Error())
+}
+
+func TestJWTServiceExpireNotBefore(t *testing.T) {
+	k := store.NewJWTKeyHMAC("k1", []byte(`s3cr3t`))
+	js := store.NewJWTService(storeManagerRequestStore, store.SetJWTKeys(k), store.SetJWTExpire(-time.Minute))
+	ts, err := js.NewToken(nil, nil)
+	assert.NoError(t, err)
+	_, err = js.Parse(ts)
+	assert.EqualError(t, err, store.ErrJWTTokenExpired.Error())
+
+	tk := jwt.New(jwt.SigningMethodHS256)
+	tk.Header[store.JWTHeaderKeyID] = "k1"
+	tk.Claims[store.ClaimNotBefore] = time.Now().Add(time.Hour).Unix()
+	ts, err = tk.SignedString(k.Private)
+	assert.NoError(t, err)
+	_, err = js.Parse(ts)
+	assert.EqualError(t, err, store.ErrJWTTokenNotValidYet.Error())
+
+	_, err = js.Parse("a.b.c")
+	assert.EqualError(t, err, store.ErrJWTTokenInvalid.Error())
+
+	// tokens without exp or jti never expire or cannot be revoked
+	for _, claims := range []map[string]interface{}{
+		{store.ClaimID: "id"},
+		{store.ClaimExpire: time.Now().Add(time.Hour).Unix()},
+		{store.ClaimExpire: time.Now().Add(time.Hour).Unix(), store.ClaimID: ""},
+	} {
+		tk := jwt.New(jwt.SigningMethodHS256)
+		tk.Header[store.JWTHeaderKeyID] = "k1"
+		tk.Claims = claims
+		ts, err = tk.SignedString(k.Private)
+		assert.NoError(t, err)
+		_, err = js.Parse(ts)
+		assert.EqualError(t, err, store.ErrJWTTokenInvalid.Error(), "%v", claims)
+	}
+}
+
+func TestJWTServiceKeyRotation(t *testing.T) {
+	rk, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+
+	js := store.NewJWTService(storeManagerRequestStore, store.SetJWTKeys(store.NewJWTKeyHMAC("2015-01", []byte(`old`))))
+	oldToken, err := js.NewToken(nil, nil)
+	assert.NoError(t, err)
+
+	js.AddKey(store.NewJWTKeyHMAC("2015-02", []byte(`new`)), true)
+	newToken, err := js.NewToken(nil, nil)
+	assert.NoError(t, err)
+
+	tk, err := js.Parse(oldToken)
+	assert.NoError(t, err)
+	assert.Exactly(t, "2015-01", tk.Header[store.JWTHeaderKeyID])
+	tk, err = js.Parse(newToken)
+	assert.NoError(t, err)
+	assert.Exactly(t, "2015-02", tk.Header[store.JWTHeaderKeyID])
+
+	js.RemoveKey("2015-01")
+	_, err = js.Parse(oldToken)
+	assert.EqualError(t, err, store.ErrJWTKeyNotFound.Error())
+
+	// HMAC token with the kid of a RSA key must be rejected
+	js.AddKey(store.NewJWTKeyRSA("rsa", rk), false)
+	forged := jwt.New(jwt.SigningMethodHS256)
+	forged.Header[store.JWTHeaderKeyID] = "rsa"
+	fs, err := forged.SignedString([]byte(`new`))
+	assert.NoError(t, err)
+	_, err = js.Parse(fs)
+	assert.EqualError(t, err, store.ErrJWTKeyMethodMismatch.Error())
+}
+
+func TestJWTServiceRevoke(t *testing.T) {
+	js := store.NewJWTService(storeManagerRequestStore, store.SetJWTKeys(store.NewJWTKeyHMAC("k1", []byte(`s3cr3t`))))
+	ts, err := js.NewToken(nil, nil)
+	assert.NoError(t, err)
+	tk, err := js.Parse(ts)
+	assert.NoError(t, err)
+
+	assert.NoError(t, js.Revoke(tk))
+	_, err = js.Parse(ts)
+	assert.EqualError(t, err, store.ErrJWTTokenBlacklisted.Error())
+
+	exp := time.Now().Add(time.Hour)
+	for _, v := range []interface{}{float64(exp.Unix()), exp.Unix(), json.Number(strconv.FormatInt(exp.Unix(), 10))} {
+		tk := &jwt.Token{Claims: map[string]interface{}{store.ClaimID: "id", store.ClaimExpire: v}}
+		js.Blacklist = store.NewBlacklistMemory()
+		assert.NoError(t, js.Revoke(tk))
+		assert.True(t, js.Blacklist.Has("id"), "%T", v)
+	}
+
+	// no or an unknown expiration must not report a successful revocation
+	for _, claims := range []map[string]interface{}{
+		{store.ClaimID: "id"},
+		{store.ClaimID: "id", store.ClaimExpire: "tomorrow"},
+	} {
+		js.Blacklist = store.NewBlacklistMemory()
+		assert.EqualError(t, js.Revoke(&jwt.Token{Claims: claims}), store.ErrJWTTokenInvalid.Error(), "%v", claims)
+		assert.False(t, js.Blacklist.Has("id"))
+	}
+
+	js.Blacklist = nil
+	assert.EqualError(t, js.Revoke(tk), store.ErrJWTNoBlacklist.Error())
+
+	bl := store.NewBlacklistMemory()
+	assert.NoError(t, bl.Set("a", -time.Second))
+	assert.False(t, bl.Has("a"))
+	assert.NoError(t, bl.Set("b", time.Minute))
+	assert.True(t, bl.Has("b"))
+}
+
+func TestJWTServiceInitByRequest(t *testing.T) {
+	defer storeManagerRequestStore.ClearCache(true)
+	assert.NoError(t, storeManagerRequestStore.Init(config.ScopeID(1), config.ScopeGroupID))
+
+	js := store.NewJWTService(storeManagerRequestStore, store.SetJWTKeys(store.NewJWTKeyHMAC("k1", []byte(`s3cr3t`))))
+	de, err := storeManagerRequestStore.Store(config.ScopeCode("de"))
+	assert.NoError(t, err)
+	ts, err := js.NewToken(de, nil)
+	assert.NoError(t, err)
+
+	req := getTestRequest(t, "GET", "http://cs.io", nil)
+	_, _, err = js.InitByRequest(req, config.ScopeGroupID)
+	assert.EqualError(t, err, store.ErrJWTTokenMissing.Error())
+
+	req.Header.Set(store.HTTPHeaderAuthorization, "Bearer "+ts)
+	s, tk, err := js.InitByRequest(req, config.ScopeGroupID)
+	assert.NoError(t, err)
+	assert.NotNil(t, tk)
+	if assert.NotNil(t, s) {
+		assert.Exactly(t, "de", s.Data().Code.String)
+	}
+
+	_, _, err = js.InitByRequest(req, config.ScopeWebsiteID+100)
+	assert.Error(t, err)
+
+	_, err = js.ParseFromRequest(&http.Request{Header: http.Header{store.HTTPHeaderAuthorization: []string{"Basic Zm9vOmJhcg=="}}})
+	assert.EqualError(t, err, store.ErrJWTTokenMissing.Error())
+}
@@ -0,0 +1,151 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/juju/errgo"
+	"golang.org/x/text/language"
+)
+
+// HTTPHeaderAcceptLanguage contains the preferred languages of the browser.
+const HTTPHeaderAcceptLanguage = "Accept-Language"
+
+// Values for the configuration path web/url/accept_language.
+const (
+	// AcceptLanguageDisabled the Accept-Language header will be ignored.
+	AcceptLanguageDisabled = `0` // must be string
+	// AcceptLanguageStore the matching store view will be used for the request.
+	AcceptLanguageStore = `1` // must be string
+	// AcceptLanguageRedirect the client gets redirected to the matching store view.
+	AcceptLanguageRedirect = `2` // must be string
+)
+
+// ErrStoreRedirected InitByRequest() has redirected the client to another
+// store view. The response has already been written.
+var ErrStoreRedirected = errors.New("Request has been redirected to another store")
+
+// AcceptLanguageStore returns the active store whose locale matches best the
+// languages of the Accept-Language header. Only stores which GetRequestStore()
+// allows for the scope type will be considered. Returns nil, nil if the header
+// is empty, no store matches with at least high confidence or the best
+// matching store is the appStore. Stores with an invalid locale are skipped.
+func (sm *Manager) AcceptLanguageStore(req *http.Request, scopeType config.ScopeGroup) (*Store, error) {
+	if sm.appStore == nil {
+		// that means you must call Init() before executing this function.
+		return nil, ErrAppStoreNotSet
+	}
+	if req == nil || req.Header.Get(HTTPHeaderAcceptLanguage) == "" {
+		return nil, nil
+	}
+	want, _, err := language.ParseAcceptLanguage(req.Header.Get(HTTPHeaderAcceptLanguage))
+	if err != nil || len(want) == 0 {
+		return nil, nil // invalid headers will be ignored
+	}
+
+	ss, err := sm.Stores()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	appID := sm.appStore.Data().StoreID
+	candidates := ss.Filter(func(s *Store) bool {
+		if s.Data().StoreID == DefaultStoreID || false == s.Data().IsActive {
+			return false
+		}
+		switch scopeType {
+		case config.ScopeStoreID:
+			return true
+		case config.ScopeGroupID:
+			return s.Data().GroupID == sm.appStore.Data().GroupID
+		case config.ScopeWebsiteID:
+			return s.Data().WebsiteID == sm.appStore.Data().WebsiteID
+		}
+		return false
+	})
+
+	// the appStore must be the first entry because the matcher falls back to it.
+	var tags []language.Tag
+	var stores StoreSlice
+	for _, s := range candidates {
+		t, err := s.Locale()
+		if err != nil {
+			continue
+		}
+		if s.Data().StoreID == appID {
+			tags = append([]language.Tag{t}, tags...)
+			stores = append(StoreSlice{s}, stores...)
+		} else {
+			tags = append(tags, t)
+			stores = append(stores, s)
+		}
+	}
+	if len(stores) == 0 {
+		return nil, nil
+	}
+
+	_, idx, conf := language.NewMatcher(tags).Match(want...)
+	if conf < language.High || idx < 0 || idx >= len(stores) || stores[idx].Data().StoreID == appID {
+		return nil, nil
+	}
+	return stores[idx], nil
+}
+
+// acceptLanguageEnabled returns true if the configuration value of
+// PathStoreAcceptLanguage enables the Accept-Language matching.
+func (sm *Manager) acceptLanguageEnabled() bool {
+	mode := sm.appStore.ConfigString(PathStoreAcceptLanguage)
+	return mode == AcceptLanguageStore || mode == AcceptLanguageRedirect
+}
+
+// initByAcceptLanguage applies the configuration value of PathStoreAcceptLanguage
+// to the store found by AcceptLanguageStore(). In redirect mode the client
+// gets redirected to the current page in the found store and
+// ErrStoreRedirected returned. The target URL always contains the ___store
+// parameter because the store cookie cannot be set without consent in cookie
+// restriction mode. Without it the next request would be redirected again.
+func (sm *Manager) initByAcceptLanguage(res http.ResponseWriter, req *http.Request, scopeType config.ScopeGroup) (*Store, error) {
+	if false == sm.acceptLanguageEnabled() {
+		return nil, nil
+	}
+	s, err := sm.AcceptLanguageStore(req, scopeType)
+	if s == nil || err != nil {
+		return nil, err
+	}
+	if sm.appStore.ConfigString(PathStoreAcceptLanguage) == AcceptLanguageRedirect && res != nil {
+		s.SetCookie(res, req)
+		http.Redirect(res, req, redirectURL(sm.appStore.SwitchURL(req, s), s), http.StatusFound)
+		return s, ErrStoreRedirected
+	}
+	return s, nil
+}
+
+// redirectURL adds the ___store parameter of the target store to rawURL if
+// it is missing.
+func redirectURL(rawURL string, target *Store) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	q := u.Query()
+	if q.Get(HTTPRequestParamStore) == "" {
+		q.Set(HTTPRequestParamStore, target.Data().Code.String)
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
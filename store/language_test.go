@@ -0,0 +1,231 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// getAcceptLanguageManager creates a manager with the stores de, at, ch
+// (inactive) in group 1, uk in group 2 and au, nz in website 2.
+func getAcceptLanguageManager(mode string) *store.Manager {
+	return getLocaleManager(mode, false)
+}
+
+// getLocaleManager same as getAcceptLanguageManager but web/url/use_store
+// and further boolean paths can be enabled.
+func getLocaleManager(mode string, useStoreInURL bool, boolPaths ...string) *store.Manager {
+	cfg := map[string]string{
+		config.MockPathScopeDefault(0, store.PathStoreAcceptLanguage): mode,
+		config.MockPathScopeDefault(0, store.PathUnsecureBaseURL):     "http://cs.io/",
+		config.MockPathScopeStore(1, directory.PathDefaultLocale):     "de_DE",
+		config.MockPathScopeStore(2, directory.PathDefaultLocale):     "de_AT",
+		config.MockPathScopeStore(3, directory.PathDefaultLocale):     "de_CH",
+		config.MockPathScopeStore(4, directory.PathDefaultLocale):     "en_GB",
+		config.MockPathScopeStore(5, directory.PathDefaultLocale):     "en_AU",
+		config.MockPathScopeStore(6, directory.PathDefaultLocale):     "en_NZ",
+	}
+	cr := config.NewMockReader(
+		config.MockString(func(path string) string {
+			return cfg[path]
+		}),
+		config.MockBool(func(path string) bool {
+			for _, p := range boolPaths {
+				if strings.HasSuffix(path, p) {
+					return true
+				}
+			}
+			return useStoreInURL && strings.HasSuffix(path, store.PathStoreInURL)
+		}),
+	)
+	return store.NewManager(
+		store.SetManagerConfig(cr),
+		store.NewStorageOption(
+			store.SetStorageConfig(cr),
+			store.SetStorageWebsites(
+				&store.TableWebsite{WebsiteID: 0, Code: dbr.NullString{NullString: sql.NullString{String: "admin", Valid: true}}, Name: dbr.NullString{NullString: sql.NullString{String: "Admin", Valid: true}}, SortOrder: 0, DefaultGroupID: 0, IsDefault: dbr.NullBool{NullBool: sql.NullBool{Bool: false, Valid: true}}},
+				&store.TableWebsite{WebsiteID: 1, Code: dbr.NullString{NullString: sql.NullString{String: "euro", Valid: true}}, Name: dbr.NullString{NullString: sql.NullString{String: "Europe", Valid: true}}, SortOrder: 0, DefaultGroupID: 1, IsDefault: dbr.NullBool{NullBool: sql.NullBool{Bool: true, Valid: true}}},
+				&store.TableWebsite{WebsiteID: 2, Code: dbr.NullString{NullString: sql.NullString{String: "oz", Valid: true}}, Name: dbr.NullString{NullString: sql.NullString{String: "OZ", Valid: true}}, SortOrder: 20, DefaultGroupID: 3, IsDefault: dbr.NullBool{NullBool: sql.NullBool{Bool: false, Valid: true}}},
+			),
+			store.SetStorageGroups(
+				&store.TableGroup{GroupID: 3, WebsiteID: 2, Name: "Australia", RootCategoryID: 2, DefaultStoreID: 5},
+				&store.TableGroup{GroupID: 1, WebsiteID: 1, Name: "DACH Group", RootCategoryID: 2, DefaultStoreID: 2},
+				&store.TableGroup{GroupID: 0, WebsiteID: 0, Name: "Default", RootCategoryID: 0, DefaultStoreID: 0},
+				&store.TableGroup{GroupID: 2, WebsiteID: 1, Name: "UK Group", RootCategoryID: 2, DefaultStoreID: 4},
+			),
+			store.SetStorageStores(
+				&store.TableStore{StoreID: 0, Code: dbr.NullString{NullString: sql.NullString{String: "admin", Valid: true}}, WebsiteID: 0, GroupID: 0, Name: "Admin", SortOrder: 0, IsActive: true},
+				&store.TableStore{StoreID: 5, Code: dbr.NullString{NullString: sql.NullString{String: "au", Valid: true}}, WebsiteID: 2, GroupID: 3, Name: "Australia", SortOrder: 10, IsActive: true},
+				&store.TableStore{StoreID: 1, Code: dbr.NullString{NullString: sql.NullString{String: "de", Valid: true}}, WebsiteID: 1, GroupID: 1, Name: "Germany", SortOrder: 10, IsActive: true},
+				&store.TableStore{StoreID: 4, Code: dbr.NullString{NullString: sql.NullString{String: "uk", Valid: true}}, WebsiteID: 1, GroupID: 2, Name: "UK", SortOrder: 10, IsActive: true},
+				&store.TableStore{StoreID: 2, Code: dbr.NullString{NullString: sql.NullString{String: "at", Valid: true}}, WebsiteID: 1, GroupID: 1, Name: "Österreich", SortOrder: 20, IsActive: true},
+				&store.TableStore{StoreID: 6, Code: dbr.NullString{NullString: sql.NullString{String: "nz", Valid: true}}, WebsiteID: 2, GroupID: 3, Name: "Kiwi", SortOrder: 30, IsActive: true},
+				&store.TableStore{IsActive: false, StoreID: 3, Code: dbr.NullString{NullString: sql.NullString{String: "ch", Valid: true}}, WebsiteID: 1, GroupID: 1, Name: "Schweiz", SortOrder: 30},
+			),
+		),
+	)
+}
+
+func TestAcceptLanguageStore(t *testing.T) {
+	tests := []struct {
+		appStore       config.ScopeIDer
+		scopeType      config.ScopeGroup
+		acceptLanguage string
+		wantStoreCode  string // empty for nil
+	}{
+		{config.ScopeCode("at"), config.ScopeGroupID, "", ""},
+		{config.ScopeCode("at"), config.ScopeGroupID, "de-DE,de;q=0.8,en;q=0.5", "de"},
+		{config.ScopeCode("at"), config.ScopeGroupID, "de-AT", ""},
+		{config.ScopeCode("at"), config.ScopeGroupID, "de-CH", ""}, // ch is inactive, at is the appStore
+		{config.ScopeCode("at"), config.ScopeGroupID, "en-GB", ""}, // uk is in another group
+		{config.ScopeCode("at"), config.ScopeWebsiteID, "en-GB,en;q=0.8", "uk"},
+		{config.ScopeCode("at"), config.ScopeWebsiteID, "en-NZ", "uk"}, // nz is in another website
+		{config.ScopeCode("at"), config.ScopeStoreID, "en-NZ", "nz"},
+		{config.ScopeCode("at"), config.ScopeStoreID, "ja-JP,ja;q=0.9", ""},
+		{config.ScopeCode("at"), config.ScopeStoreID, "\U0001f631", ""},
+	}
+	for _, test := range tests {
+		m := getAcceptLanguageManager(store.AcceptLanguageStore)
+		if test.scopeType == config.ScopeStoreID {
+			assert.NoError(t, m.Init(test.appStore, config.ScopeStoreID))
+		} else {
+			assert.NoError(t, m.Init(config.ScopeID(1), test.scopeType))
+		}
+
+		req := getTestRequest(t, "GET", "http://cs.io", nil)
+		req.Header.Set(store.HTTPHeaderAcceptLanguage, test.acceptLanguage)
+		s, err := m.AcceptLanguageStore(req, test.scopeType)
+		assert.NoError(t, err, "%#v", test)
+		if test.wantStoreCode == "" {
+			assert.Nil(t, s, "%#v", test)
+		} else if assert.NotNil(t, s, "%#v", test) {
+			assert.Exactly(t, test.wantStoreCode, s.Data().Code.String, "%#v", test)
+		}
+	}
+
+	_, err := getAcceptLanguageManager(store.AcceptLanguageStore).AcceptLanguageStore(nil, config.ScopeStoreID)
+	assert.EqualError(t, err, store.ErrAppStoreNotSet.Error())
+}
+
+func TestInitByRequestAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		mode          string
+		cookie        *http.Cookie
+		wantStoreCode string
+		wantErr       error
+		wantLocation  string
+	}{
+		{store.AcceptLanguageDisabled, nil, "", nil, ""},
+		{store.AcceptLanguageStore, nil, "de", nil, ""},
+		{store.AcceptLanguageStore, &http.Cookie{Name: store.CookieName, Value: "at"}, "at", nil, ""},
+		{store.AcceptLanguageRedirect, nil, "de", store.ErrStoreRedirected, "http://cs.io/?___store=de"},
+	}
+	for _, test := range tests {
+		m := getAcceptLanguageManager(test.mode)
+		assert.NoError(t, m.Init(config.ScopeID(1), config.ScopeGroupID))
+
+		req := getTestRequest(t, "GET", "http://cs.io/", test.cookie)
+		req.Header.Set(store.HTTPHeaderAcceptLanguage, "de-DE,de;q=0.8")
+		rec := httptest.NewRecorder()
+		s, err := m.InitByRequest(rec, req, config.ScopeGroupID)
+		if test.wantErr != nil {
+			assert.EqualError(t, err, test.wantErr.Error(), "%#v", test)
+		} else {
+			assert.NoError(t, err, "%#v", test)
+		}
+		if test.wantStoreCode == "" {
+			assert.Nil(t, s, "%#v", test)
+		} else if assert.NotNil(t, s, "%#v", test) {
+			assert.Exactly(t, test.wantStoreCode, s.Data().Code.String, "%#v", test)
+		}
+		if test.wantLocation != "" {
+			assert.Exactly(t, http.StatusFound, rec.Code)
+			assert.Exactly(t, test.wantLocation, rec.Header().Get("Location"))
+			assert.Contains(t, rec.Header().Get("Set-Cookie"), store.CookieName+"=de;")
+		}
+	}
+}
+
+func TestInitByRequestAcceptLanguageRedirectWithoutCookie(t *testing.T) {
+	m := getLocaleManager(store.AcceptLanguageRedirect, false, store.PathCookieRestriction)
+	assert.NoError(t, m.Init(config.ScopeID(1), config.ScopeGroupID))
+
+	// cookie restriction mode without consent: the store cookie cannot be set
+	req := getTestRequest(t, "GET", "http://cs.io/catalog/product/view?id=3", nil)
+	req.Header.Set(store.HTTPHeaderAcceptLanguage, "de-DE,de;q=0.8")
+	rec := httptest.NewRecorder()
+	_, err := m.InitByRequest(rec, req, config.ScopeGroupID)
+	assert.EqualError(t, err, store.ErrStoreRedirected.Error())
+	assert.Exactly(t, http.StatusFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("Set-Cookie"))
+	location := rec.Header().Get("Location")
+	assert.Exactly(t, "http://cs.io/catalog/product/view?___store=de&id=3", location)
+
+	// following the redirect must not redirect again
+	req = getTestRequest(t, "GET", location, nil)
+	req.Header.Set(store.HTTPHeaderAcceptLanguage, "de-DE,de;q=0.8")
+	rec = httptest.NewRecorder()
+	s, err := m.InitByRequest(rec, req, config.ScopeGroupID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, s) {
+		assert.Exactly(t, "de", s.Data().Code.String)
+	}
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Location"))
+}
+
+func TestInitByRequestAcceptLanguageDefaultStoreChoice(t *testing.T) {
+	m := getAcceptLanguageManager(store.AcceptLanguageRedirect)
+	assert.NoError(t, m.Init(config.ScopeID(1), config.ScopeGroupID))
+
+	// at is the default store of the website but the browser prefers de
+	req := getTestRequest(t, "GET", "http://cs.io/?___store=at", nil)
+	req.Header.Set(store.HTTPHeaderAcceptLanguage, "de-DE,de;q=0.8")
+	rec := httptest.NewRecorder()
+	s, err := m.InitByRequest(rec, req, config.ScopeGroupID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, s) {
+		assert.Exactly(t, "at", s.Data().Code.String)
+	}
+	cookies := (&http.Response{Header: rec.Header()}).Cookies()
+	if !assert.Len(t, cookies, 1) {
+		return
+	}
+	assert.Exactly(t, store.CookieName, cookies[0].Name)
+	assert.Exactly(t, "at", cookies[0].Value)
+	assert.True(t, cookies[0].MaxAge >= 0, "cookie must not be deleted")
+
+	// the next request keeps the chosen store and does not redirect
+	req = getTestRequest(t, "GET", "http://cs.io/", cookies[0])
+	req.Header.Set(store.HTTPHeaderAcceptLanguage, "de-DE,de;q=0.8")
+	rec = httptest.NewRecorder()
+	s, err = m.InitByRequest(rec, req, config.ScopeGroupID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, s) {
+		assert.Exactly(t, "at", s.Data().Code.String)
+	}
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Location"))
+}
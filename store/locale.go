@@ -0,0 +1,178 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/juju/errgo"
+	"golang.org/x/text/language"
+)
+
+var (
+	// NewNumberFormatter creates the number formatter for a locale. Change this
+	// function if you have locale specific symbols and formats available. The
+	// default function returns the i18n default formatter for all locales,
+	// which uses the English symbols and formats.
+	NewNumberFormatter = func(_ language.Tag) (i18n.NumberFormatter, error) {
+		return i18n.NewNumber(), nil
+	}
+	// NewCurrencyFormatter creates the currency formatter for a locale and a
+	// currency. Change this function if you have locale specific symbols and
+	// formats available. The default function returns the i18n default
+	// formatter for all locales and sets only the currency.
+	NewCurrencyFormatter = func(_ language.Tag, c language.Currency) (i18n.CurrencyFormatter, error) {
+		return i18n.NewCurrency(i18n.CurrencyISO(c.String())), nil
+	}
+)
+
+// localeCache contains the parsed locale settings of a Store. Each entry
+// remembers the raw configuration value from which it has been created. If
+// the configuration value changes the entry will be created again.
+type localeCache struct {
+	mu sync.Mutex
+
+	localeCode string
+	tag        language.Tag
+
+	timezone string
+	location *time.Location
+
+	numberLocale string
+	number       i18n.NumberFormatter
+
+	currencyLocale string
+	// currencies key is the 3-letter ISO currency code
+	currencies map[string]i18n.CurrencyFormatter
+}
+
+func (lc *localeCache) reset() {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.localeCode, lc.tag = "", language.Und
+	lc.timezone, lc.location = "", nil
+	lc.numberLocale, lc.number = "", nil
+	lc.currencyLocale, lc.currencies = "", nil
+}
+
+// Locale returns the language tag of the store parsed from the configuration
+// value general/locale/code. The Magento notation with an underscore like
+// de_CH is supported.
+func (s *Store) Locale() (language.Tag, error) {
+	code := s.ConfigString(directory.PathDefaultLocale)
+	s.lc.mu.Lock()
+	defer s.lc.mu.Unlock()
+	return s.locale(code)
+}
+
+// locale parses the locale code. Cache must be locked.
+func (s *Store) locale(code string) (language.Tag, error) {
+	if s.lc.localeCode == code && code != "" {
+		return s.lc.tag, nil
+	}
+	t, err := i18n.GetLocaleTag(code)
+	if err != nil {
+		return language.Und, errgo.Mask(err)
+	}
+	s.lc.localeCode, s.lc.tag = code, t
+	return t, nil
+}
+
+// Location returns the time zone of the store loaded from the configuration
+// value general/locale/timezone. An empty value returns time.UTC.
+func (s *Store) Location() (*time.Location, error) {
+	tz := s.ConfigString(directory.PathDefaultTimezone)
+	s.lc.mu.Lock()
+	defer s.lc.mu.Unlock()
+	if s.lc.location != nil && s.lc.timezone == tz {
+		return s.lc.location, nil
+	}
+	l, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	s.lc.timezone, s.lc.location = tz, l
+	return l, nil
+}
+
+// NumberFormatter returns the number formatter for the locale of the store.
+// The formatter gets created with NewNumberFormatter.
+func (s *Store) NumberFormatter() (i18n.NumberFormatter, error) {
+	code := s.ConfigString(directory.PathDefaultLocale)
+	s.lc.mu.Lock()
+	defer s.lc.mu.Unlock()
+	t, err := s.locale(code)
+	if err != nil {
+		return nil, err
+	}
+	if s.lc.number == nil || s.lc.numberLocale != code {
+		nf, err := NewNumberFormatter(t)
+		if err != nil {
+			return nil, errgo.Mask(err, errgo.Any)
+		}
+		s.lc.numberLocale, s.lc.number = code, nf
+	}
+	return s.lc.number, nil
+}
+
+// CurrencyFormatter returns the currency formatter for the locale of the
+// store and its default display currency, see DefaultCurrencyCode(). The
+// formatter gets created with NewCurrencyFormatter.
+func (s *Store) CurrencyFormatter() (i18n.CurrencyFormatter, error) {
+	c, err := s.DefaultCurrencyCode()
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	return s.currencyFormatter(c.String())
+}
+
+// currencyFormatter returns the cached currency formatter for a 3-letter ISO
+// currency code.
+func (s *Store) currencyFormatter(cur string) (i18n.CurrencyFormatter, error) {
+	code := s.ConfigString(directory.PathDefaultLocale)
+	s.lc.mu.Lock()
+	defer s.lc.mu.Unlock()
+	if cf, ok := s.lc.currencies[cur]; ok && s.lc.currencyLocale == code {
+		return cf, nil
+	}
+	t, err := s.locale(code)
+	if err != nil {
+		return nil, err
+	}
+	c, err := language.ParseCurrency(cur)
+	if err != nil {
+		return nil, errgo.Mask(err)
+	}
+	cf, err := NewCurrencyFormatter(t, c)
+	if err != nil {
+		return nil, errgo.Mask(err, errgo.Any)
+	}
+	if s.lc.currencies == nil || s.lc.currencyLocale != code {
+		s.lc.currencyLocale, s.lc.currencies = code, make(map[string]i18n.CurrencyFormatter)
+	}
+	s.lc.currencies[cur] = cf
+	return cf, nil
+}
+
+// ResetLocale clears the cached language tag, time zone and formatters. They
+// will be created again on the next access. Changed configuration values get
+// detected automatically, so calling ResetLocale is only needed after
+// NewNumberFormatter or NewCurrencyFormatter have been replaced.
+func (s *Store) ResetLocale() {
+	s.lc.reset()
+}
@@ -0,0 +1,136 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/directory"
+	"github.com/corestoreio/csfw/i18n"
+	"github.com/corestoreio/csfw/store"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestStoreLocale(t *testing.T) {
+	cfg := map[string]string{
+		config.MockPathScopeStore(1, directory.PathDefaultLocale):   "de_CH",
+		config.MockPathScopeStore(1, directory.PathDefaultTimezone): "Europe/Zurich",
+	}
+	s := newURLTestStore(cfg, nil)
+
+	tag, err := s.Locale()
+	assert.NoError(t, err)
+	assert.Exactly(t, language.MustParse("de-CH"), tag)
+
+	loc, err := s.Location()
+	assert.NoError(t, err)
+	assert.Exactly(t, "Europe/Zurich", loc.String())
+	loc2, err := s.Location()
+	assert.NoError(t, err)
+	assert.True(t, loc == loc2, "Location must be cached")
+
+	// config change invalidates the cache
+	cfg[config.MockPathScopeStore(1, directory.PathDefaultLocale)] = "fr_CH"
+	cfg[config.MockPathScopeStore(1, directory.PathDefaultTimezone)] = "Europe/Paris"
+	tag, err = s.Locale()
+	assert.NoError(t, err)
+	assert.Exactly(t, language.MustParse("fr-CH"), tag)
+	loc, err = s.Location()
+	assert.NoError(t, err)
+	assert.Exactly(t, "Europe/Paris", loc.String())
+
+	cfg[config.MockPathScopeStore(1, directory.PathDefaultLocale)] = "de_€uro"
+	cfg[config.MockPathScopeStore(1, directory.PathDefaultTimezone)] = "Mars/Olympus_Mons"
+	_, err = s.Locale()
+	assert.Error(t, err)
+	_, err = s.Location()
+	assert.Error(t, err)
+	_, err = s.NumberFormatter()
+	assert.Error(t, err)
+}
+
+func TestStoreFormatter(t *testing.T) {
+	cfg := map[string]string{
+		config.MockPathScopeStore(1, directory.PathDefaultLocale):   "de_DE",
+		config.MockPathScopeStore(1, directory.PathCurrencyDefault): "EUR",
+	}
+	s := newURLTestStore(cfg, nil)
+
+	nf, err := s.NumberFormatter()
+	assert.NoError(t, err)
+	nf2, err := s.NumberFormatter()
+	assert.NoError(t, err)
+	assert.True(t, nf == nf2, "NumberFormatter must be cached")
+
+	var buf bytes.Buffer
+	_, err = nf.FmtInt64(&buf, 1234)
+	assert.NoError(t, err)
+	assert.Exactly(t, "1,234.000", buf.String())
+
+	cf, err := s.CurrencyFormatter()
+	assert.NoError(t, err)
+	assert.Exactly(t, "EUR", string(cf.Sign()))
+	cf2, err := s.CurrencyFormatter()
+	assert.NoError(t, err)
+	assert.True(t, cf == cf2, "CurrencyFormatter must be cached")
+
+	cfg[config.MockPathScopeStore(1, directory.PathCurrencyDefault)] = "CHF"
+	cf, err = s.CurrencyFormatter()
+	assert.NoError(t, err)
+	assert.Exactly(t, "CHF", string(cf.Sign()))
+
+	cfg[config.MockPathScopeStore(1, directory.PathCurrencyDefault)] = "EURO"
+	_, err = s.CurrencyFormatter()
+	assert.Error(t, err)
+
+	// the default formatters do not depend on the locale
+	cfg[config.MockPathScopeStore(1, directory.PathDefaultLocale)] = "fr_FR"
+	cfg[config.MockPathScopeStore(1, directory.PathCurrencyDefault)] = "EUR"
+	nf, err = s.NumberFormatter()
+	assert.NoError(t, err)
+	buf.Reset()
+	_, err = nf.FmtInt64(&buf, 1234)
+	assert.NoError(t, err)
+	assert.Exactly(t, "1,234.000", buf.String())
+	cf, err = s.CurrencyFormatter()
+	assert.NoError(t, err)
+	assert.Exactly(t, "EUR", string(cf.Sign()))
+}
+
+func TestStoreFormatterFactory(t *testing.T) {
+	defer func(nf func(language.Tag) (i18n.NumberFormatter, error)) { store.NewNumberFormatter = nf }(store.NewNumberFormatter)
+
+	var called language.Tag
+	store.NewNumberFormatter = func(tag language.Tag) (i18n.NumberFormatter, error) {
+		called = tag
+		return i18n.NewNumber(i18n.NumberFormat("#,##0.00", i18n.Symbols{Decimal: ',', Group: '.'})), nil
+	}
+
+	s := newURLTestStore(map[string]string{
+		config.MockPathScopeStore(1, directory.PathDefaultLocale): "de_DE",
+	}, nil)
+	s.ResetLocale()
+	nf, err := s.NumberFormatter()
+	assert.NoError(t, err)
+	assert.Exactly(t, language.MustParse("de-DE"), called)
+
+	var buf bytes.Buffer
+	_, err = nf.FmtFloat64(&buf, 1234.5)
+	assert.NoError(t, err)
+	assert.Exactly(t, "1.234,50", buf.String())
+}
@@ -136,9 +136,16 @@ func (sm *Manager) Init(scopeCode config.ScopeIDer, scopeType config.ScopeGroup)
 
 // InitByRequest returns a new Store read from a cookie or HTTP request param.
 // The internal appStore must be set before hand.
-// 1. check cookie store, always a string and the store code
-// 2. check for ___store variable, always a string and the store code
-// 3. May return nil,nil if nothing is set.
+//  1. check cookie store, always a string and the store code
+//  2. check for ___store variable, always a string and the store code
+//  3. if enabled in web/url/accept_language match the Accept-Language header
+//     against the locales of the stores, see AcceptLanguageStore(). In redirect
+//     mode the error ErrStoreRedirected will be returned and the response has
+//     already been written. If enabled, a ___store parameter with the default
+//     store of the website sets the store cookie instead of deleting it, so
+//     that the explicit choice wins over the Accept-Language header.
+//  4. May return nil,nil if nothing is set.
+//
 // This function must be used within an HTTP handler.
 // The returned new Store must be used in the HTTP context and overrides the appStore.
 // @see \Magento\Store\Model\StorageFactory::_reinitStores
@@ -157,7 +164,7 @@ func (sm *Manager) InitByRequest(res http.ResponseWriter, req *http.Request, sco
 		var err error
 		// @todo reqStoreCode if number ... cast to int64 because then group id if ScopeGroup is group.
 		if reqStore, err = sm.GetRequestStore(config.ScopeCode(reqStoreCode), scopeType); err != nil {
-			return nil, errgo.Mask(err)
+			return nil, errgo.Mask(err, errgo.Any)
 		}
 		// also delete and re-set a new cookie
 		if reqStore != nil && reqStore.Data().Code.String == reqStoreCode {
@@ -165,13 +172,18 @@ func (sm *Manager) InitByRequest(res http.ResponseWriter, req *http.Request, sco
 			if err != nil {
 				return nil, errgo.Mask(err)
 			}
-			if wds.Data().Code.String == reqStoreCode {
-				reqStore.DeleteCookie(res) // cookie not needed anymore
+			if wds.Data().Code.String == reqStoreCode && false == sm.acceptLanguageEnabled() {
+				reqStore.DeleteCookie(res, req) // cookie not needed anymore
 			} else {
-				reqStore.SetCookie(res) // make sure we force set the new store
+				// with Accept-Language matching the cookie keeps also the choice of the default store
+				reqStore.SetCookie(res, req) // make sure we force set the new store
 			}
 		}
 	}
+
+	if reqStore == nil {
+		return sm.initByAcceptLanguage(res, req, scopeType)
+	}
 	return reqStore, nil // can be nil,nil
 }
 
@@ -205,7 +217,7 @@ func (sm *Manager) GetRequestStore(r config.ScopeIDer, scopeType config.ScopeGro
 	activeStore, err := sm.activeStore(r) // this is the active store from Cookie or Request.
 	if activeStore == nil || err != nil {
 		// store is not active so ignore
-		return nil, errgo.Mask(err)
+		return nil, errgo.Mask(err, errgo.Any)
 	}
 
 	allowStoreChange := false
@@ -246,7 +258,9 @@ func (sm *Manager) HasSingleStore() bool {
 }
 
 // Website returns the cached Website pointer from an ID or code including all of its
-// groups and all related stores. It panics when the integrity is incorrect.
+// groups and all related stores. Broken relations cannot
+// panic anymore if the data has been loaded via ReInit() because it returns
+// an *IntegrityReport error instead.
 // If ID and code are available then the non-empty code has precedence.
 // If no argument has been supplied then the Website of the internal appStore
 // will be returned. If more than one argument has been provided it returns an error.
@@ -272,11 +286,12 @@ func (sm *Manager) Website(r ...config.ScopeIDer) (*Website, error) {
 
 	w, err := sm.storage.Website(r[0])
 	sm.websiteMap[key] = w
-	return sm.websiteMap[key], errgo.Mask(err)
+	return sm.websiteMap[key], errgo.Mask(err, errgo.Any)
 }
 
 // Websites returns a cached slice containing all pointers to Websites with its associated
-// groups and stores. It panics when the integrity is incorrect.
+// groups and stores. See Website() about
+// the integrity of the data.
 func (sm *Manager) Websites() (WebsiteSlice, error) {
 	if sm.websites != nil {
 		return sm.websites, nil
@@ -312,11 +327,12 @@ func (sm *Manager) Group(r ...config.ScopeIDer) (*Group, error) {
 
 	g, err := sm.storage.Group(r[0])
 	sm.groupMap[key] = g
-	return sm.groupMap[key], errgo.Mask(err)
+	return sm.groupMap[key], errgo.Mask(err, errgo.Any)
 }
 
 // Groups returns a cached slice containing all pointers to Groups with its associated
-// stores and websites. It panics when the integrity is incorrect.
+// stores and websites. See Website()
+// about the integrity of the data.
 func (sm *Manager) Groups() (GroupSlice, error) {
 	if sm.groups != nil {
 		return sm.groups, nil
@@ -352,7 +368,7 @@ func (sm *Manager) Store(r ...config.ScopeIDer) (*Store, error) {
 
 	s, err := sm.storage.Store(r[0])
 	sm.storeMap[key] = s
-	return sm.storeMap[key], errgo.Mask(err)
+	return sm.storeMap[key], errgo.Mask(err, errgo.Any)
 }
 
 // Stores returns a cached Store slice. Can return an error when the website or
@@ -392,6 +408,8 @@ func (sm *Manager) activeStore(r config.ScopeIDer) (*Store, error) {
 
 // ReInit reloads the website, store group and store view data from the database.
 // After reloading internal cache will be cleared if there are no errors.
+// Hard violations of the integrity will be returned as *IntegrityReport and
+// the previously loaded data will be kept.
 func (sm *Manager) ReInit(dbrSess dbr.SessionRunner, cbs ...csdb.DbrSelectCb) error {
 	err := sm.storage.ReInit(dbrSess, cbs...)
 	if err == nil {
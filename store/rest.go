@@ -14,5 +14,369 @@
 
 package store
 
-//@todo routes to implement GH Issue#1
-//
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/juju/errgo"
+	"golang.org/x/text/language"
+)
+
+// Routes for the REST API. Mount the RESTHandler with http.StripPrefix()
+// if the API should be available below a prefix like /api/v1.
+const (
+	// RouteWebsites lists all websites. RouteWebsites + "{id|code}" returns one website.
+	RouteWebsites = "/websites/"
+	// RouteGroups lists all groups. RouteGroups + "{id}" returns one group.
+	RouteGroups = "/groups/"
+	// RouteStores lists all stores. RouteStores + "{id|code}" returns one store.
+	RouteStores = "/stores/"
+	// RouteStoreCurrent returns the store resolved via Manager.InitByRequest().
+	RouteStoreCurrent = RouteStores + "current"
+)
+
+type (
+	// RESTHandler serves the websites, groups and stores of a Manager as JSON.
+	// Only GET and HEAD requests are allowed. The admin website, group and
+	// store with ID 0 and inactive stores are hidden by default.
+	RESTHandler struct {
+		m *Manager
+		// scopeType is used for InitByRequest when resolving the current store.
+		scopeType config.ScopeGroup
+		// IncludeAdmin exposes the admin website, group and store with ID 0.
+		IncludeAdmin bool
+		// IncludeInactive exposes stores which are not active.
+		IncludeInactive bool
+	}
+
+	// RESTWebsite JSON representation of a Website including derived data.
+	RESTWebsite struct {
+		ID             int64   `json:"id"`
+		Code           string  `json:"code"`
+		Name           string  `json:"name"`
+		SortOrder      int64   `json:"sort_order"`
+		DefaultGroupID int64   `json:"default_group_id"`
+		IsDefault      bool    `json:"is_default"`
+		BaseCurrency   string  `json:"base_currency"`
+		GroupIDs       []int64 `json:"group_ids"`
+		StoreIDs       []int64 `json:"store_ids"`
+	}
+
+	// RESTGroup JSON representation of a Group.
+	RESTGroup struct {
+		ID             int64   `json:"id"`
+		WebsiteID      int64   `json:"website_id"`
+		Name           string  `json:"name"`
+		RootCategoryID int64   `json:"root_category_id"`
+		DefaultStoreID int64   `json:"default_store_id"`
+		StoreIDs       []int64 `json:"store_ids"`
+	}
+
+	// RESTStore JSON representation of a Store including derived data. All
+	// URLs depend on the current request, see Store.URL().
+	RESTStore struct {
+		ID           int64  `json:"id"`
+		Code         string `json:"code"`
+		WebsiteID    int64  `json:"website_id"`
+		GroupID      int64  `json:"group_id"`
+		Name         string `json:"name"`
+		SortOrder    int64  `json:"sort_order"`
+		IsActive     bool   `json:"is_active"`
+		BaseURL      string `json:"base_url"`
+		StaticURL    string `json:"static_url"`
+		MediaURL     string `json:"media_url"`
+		Locale       string `json:"locale"` // BCP 47 tag like de-CH
+		BaseCurrency string `json:"base_currency"`
+	}
+
+	restError struct {
+		Error string `json:"error"`
+	}
+)
+
+var _ http.Handler = (*RESTHandler)(nil)
+
+// NewRESTHandler creates a new handler for the store REST API. The scope type
+// will be passed to Manager.InitByRequest() to resolve the current store.
+func NewRESTHandler(m *Manager, scopeType config.ScopeGroup) *RESTHandler {
+	if m == nil {
+		panic(ErrStoreNewArgNil)
+	}
+	return &RESTHandler{
+		m:         m,
+		scopeType: scopeType,
+	}
+}
+
+// ServeHTTP dispatches the request to the routes defined in the Route* constants.
+func (h *RESTHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		restWriteError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+
+	p := strings.TrimRight(r.URL.Path, "/") + "/"
+	switch {
+	case p == RouteStoreCurrent+"/":
+		h.serveCurrentStore(w, r)
+	case strings.HasPrefix(p, RouteWebsites):
+		h.serveWebsites(w, r, restParam(p, RouteWebsites))
+	case strings.HasPrefix(p, RouteGroups):
+		h.serveGroups(w, r, restParam(p, RouteGroups))
+	case strings.HasPrefix(p, RouteStores):
+		h.serveStores(w, r, restParam(p, RouteStores))
+	default:
+		restWriteError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	}
+}
+
+func (h *RESTHandler) serveWebsites(w http.ResponseWriter, r *http.Request, param string) {
+	if param == "" {
+		ws, err := h.m.Websites()
+		if err != nil {
+			restWriteErr(w, err)
+			return
+		}
+		rws := make([]*RESTWebsite, 0, len(ws))
+		for _, website := range ws {
+			if h.visibleWebsite(website) {
+				rws = append(rws, NewRESTWebsite(website))
+			}
+		}
+		restWriteJSON(w, rws)
+		return
+	}
+
+	id, err := restScopeIDer(param, true)
+	if err != nil {
+		restWriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	website, err := h.m.Website(id)
+	if err == nil && !h.visibleWebsite(website) {
+		err = ErrWebsiteNotFound
+	}
+	if err != nil {
+		restWriteErr(w, err)
+		return
+	}
+	restWriteJSON(w, NewRESTWebsite(website))
+}
+
+func (h *RESTHandler) serveGroups(w http.ResponseWriter, r *http.Request, param string) {
+	if param == "" {
+		gs, err := h.m.Groups()
+		if err != nil {
+			restWriteErr(w, err)
+			return
+		}
+		rgs := make([]*RESTGroup, 0, len(gs))
+		for _, g := range gs {
+			if h.visibleGroup(g) {
+				rgs = append(rgs, NewRESTGroup(g))
+			}
+		}
+		restWriteJSON(w, rgs)
+		return
+	}
+
+	id, err := restScopeIDer(param, false)
+	if err != nil {
+		restWriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	g, err := h.m.Group(id)
+	if err == nil && !h.visibleGroup(g) {
+		err = ErrGroupNotFound
+	}
+	if err != nil {
+		restWriteErr(w, err)
+		return
+	}
+	restWriteJSON(w, NewRESTGroup(g))
+}
+
+func (h *RESTHandler) serveStores(w http.ResponseWriter, r *http.Request, param string) {
+	if param == "" {
+		ss, err := h.m.Stores()
+		if err != nil {
+			restWriteErr(w, err)
+			return
+		}
+		rss := make([]*RESTStore, 0, len(ss))
+		for _, s := range ss {
+			if h.visibleStore(s) {
+				rss = append(rss, NewRESTStore(s, r))
+			}
+		}
+		restWriteJSON(w, rss)
+		return
+	}
+
+	id, err := restScopeIDer(param, true)
+	if err != nil {
+		restWriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s, err := h.m.Store(id)
+	if err == nil && !h.visibleStore(s) {
+		err = ErrStoreNotFound
+	}
+	if err != nil {
+		restWriteErr(w, err)
+		return
+	}
+	restWriteJSON(w, NewRESTStore(s, r))
+}
+
+// serveCurrentStore resolves the store via cookie or request parameter and
+// falls back to the appStore.
+func (h *RESTHandler) serveCurrentStore(w http.ResponseWriter, r *http.Request) {
+	s, err := h.m.InitByRequest(w, r, h.scopeType)
+	if err == ErrStoreRedirected {
+		return // response has already been written
+	}
+	if err != nil {
+		restWriteErr(w, err)
+		return
+	}
+	if s == nil {
+		if s, err = h.m.Store(); err != nil {
+			restWriteErr(w, err)
+			return
+		}
+	}
+	restWriteJSON(w, NewRESTStore(s, r))
+}
+
+func (h *RESTHandler) visibleWebsite(w *Website) bool {
+	return h.IncludeAdmin || w.Data().WebsiteID != 0
+}
+
+func (h *RESTHandler) visibleGroup(g *Group) bool {
+	return h.IncludeAdmin || g.Data().GroupID != 0
+}
+
+func (h *RESTHandler) visibleStore(s *Store) bool {
+	return (h.IncludeAdmin || s.Data().StoreID != 0) && (h.IncludeInactive || s.Data().IsActive)
+}
+
+// NewRESTWebsite creates the JSON representation of a Website.
+func NewRESTWebsite(w *Website) *RESTWebsite {
+	rw := &RESTWebsite{
+		ID:             w.Data().WebsiteID,
+		Code:           w.Data().Code.String,
+		Name:           w.Data().Name.String,
+		SortOrder:      w.Data().SortOrder,
+		DefaultGroupID: w.Data().DefaultGroupID,
+		IsDefault:      w.Data().IsDefault.Valid && w.Data().IsDefault.Bool,
+		GroupIDs:       []int64{},
+		StoreIDs:       []int64{},
+	}
+	if c, err := w.BaseCurrencyCode(); err == nil {
+		rw.BaseCurrency = c.String()
+	}
+	if gs, err := w.Groups(); err == nil {
+		rw.GroupIDs = gs.IDs()
+	}
+	if ss, err := w.Stores(); err == nil {
+		rw.StoreIDs = ss.IDs()
+	}
+	return rw
+}
+
+// NewRESTGroup creates the JSON representation of a Group.
+func NewRESTGroup(g *Group) *RESTGroup {
+	rg := &RESTGroup{
+		ID:             g.Data().GroupID,
+		WebsiteID:      g.Data().WebsiteID,
+		Name:           g.Data().Name,
+		RootCategoryID: g.Data().RootCategoryID,
+		DefaultStoreID: g.Data().DefaultStoreID,
+		StoreIDs:       []int64{},
+	}
+	if ss, err := g.Stores(); err == nil {
+		rg.StoreIDs = ss.IDs()
+	}
+	return rg
+}
+
+// NewRESTStore creates the JSON representation of a Store. The request can be
+// nil and is used to build the URLs, see Store.URL().
+func NewRESTStore(s *Store, r *http.Request) *RESTStore {
+	rs := &RESTStore{
+		ID:        s.Data().StoreID,
+		Code:      s.Data().Code.String,
+		WebsiteID: s.Data().WebsiteID,
+		GroupID:   s.Data().GroupID,
+		Name:      s.Data().Name,
+		SortOrder: s.Data().SortOrder,
+		IsActive:  s.Data().IsActive,
+		BaseURL:   s.URL(r, config.URLTypeLink, "", nil),
+		StaticURL: s.URL(r, config.URLTypeStatic, "", nil),
+		MediaURL:  s.URL(r, config.URLTypeMedia, "", nil),
+	}
+	if t, err := s.Locale(); err == nil && t != language.Und {
+		rs.Locale = t.String()
+	}
+	if c, err := s.Website().BaseCurrencyCode(); err == nil {
+		rs.BaseCurrency = c.String()
+	}
+	return rs
+}
+
+// restParam extracts the ID or code from a path. The path must end with a slash.
+func restParam(path, route string) string {
+	return strings.TrimRight(strings.TrimPrefix(path, route), "/")
+}
+
+// restScopeIDer converts a path parameter into a ScopeID or if allowed into a
+// ScopeCode.
+func restScopeIDer(param string, allowCode bool) (config.ScopeIDer, error) {
+	if id, err := strconv.ParseInt(param, 10, 64); err == nil {
+		return config.ScopeID(id), nil
+	}
+	if !allowCode {
+		return nil, ErrUnsupportedScopeGroup
+	}
+	if err := ValidateStoreCode(param); err != nil {
+		return nil, err
+	}
+	return config.ScopeCode(param), nil
+}
+
+// restWriteJSON encodes v into a buffer first, so an encoding error can still
+// be reported with status code 500.
+func restWriteJSON(w http.ResponseWriter, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		restWriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+// restWriteErr maps the known not found errors to status code 404. All other
+// errors are internal server errors.
+func restWriteErr(w http.ResponseWriter, err error) {
+	switch errgo.Cause(err) {
+	case ErrWebsiteNotFound, ErrGroupNotFound, ErrStoreNotFound:
+		restWriteError(w, http.StatusNotFound, err.Error())
+	case ErrStoreNotActive, ErrStoreChangeNotAllowed, ErrStoreCodeInvalid:
+		restWriteError(w, http.StatusBadRequest, err.Error())
+	default:
+		restWriteError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func restWriteError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(restError{Error: msg})
+}
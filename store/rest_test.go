@@ -0,0 +1,192 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func getRESTTestHandler(t *testing.T) *store.RESTHandler {
+	m := store.NewManager(store.SetManagerStorage(testStorage))
+	assert.NoError(t, m.Init(config.ScopeCode("at"), config.ScopeStoreID))
+	return store.NewRESTHandler(m, config.ScopeWebsiteID)
+}
+
+func serveREST(t *testing.T, h http.Handler, method, url string, v interface{}) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, url, nil)
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if v != nil && rec.Code == http.StatusOK {
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), v), rec.Body.String())
+	}
+	return rec
+}
+
+func TestRESTHandlerWebsites(t *testing.T) {
+	h := getRESTTestHandler(t)
+
+	var ws []store.RESTWebsite
+	rec := serveREST(t, h, "GET", "/websites", &ws)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Exactly(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Len(t, ws, 2)
+
+	var w store.RESTWebsite
+	rec = serveREST(t, h, "GET", "/websites/euro/", &w)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Exactly(t, int64(1), w.ID)
+	assert.True(t, w.IsDefault)
+	assert.Exactly(t, []int64{1, 2}, w.GroupIDs)
+	assert.Exactly(t, []int64{1, 4, 2, 3}, w.StoreIDs)
+
+	rec = serveREST(t, h, "GET", "/websites/2", &w)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Exactly(t, "oz", w.Code)
+
+	rec = serveREST(t, h, "GET", "/websites/asia", nil)
+	assert.Exactly(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), store.ErrWebsiteNotFound.Error())
+}
+
+func TestRESTHandlerGroups(t *testing.T) {
+	h := getRESTTestHandler(t)
+
+	var gs []store.RESTGroup
+	rec := serveREST(t, h, "GET", "/groups/", &gs)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Len(t, gs, 3)
+
+	var g store.RESTGroup
+	rec = serveREST(t, h, "GET", "/groups/3", &g)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Exactly(t, "Australia", g.Name)
+	assert.Exactly(t, []int64{5, 6}, g.StoreIDs)
+
+	rec = serveREST(t, h, "GET", "/groups/dach", nil)
+	assert.Exactly(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRESTHandlerStores(t *testing.T) {
+	h := getRESTTestHandler(t)
+
+	var ss []store.RESTStore
+	rec := serveREST(t, h, "GET", "/stores", &ss)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Len(t, ss, 6)
+
+	var s store.RESTStore
+	rec = serveREST(t, h, "GET", "/stores/5", &s)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Exactly(t, "au", s.Code)
+	assert.Exactly(t, int64(3), s.GroupID)
+
+	rec = serveREST(t, h, "GET", "/stores/current", &s)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Exactly(t, "at", s.Code)
+
+	rec = serveREST(t, h, "GET", "/stores/current?___store=ch", &s)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Exactly(t, "ch", s.Code)
+
+	rec = serveREST(t, h, "GET", "/stores/current?___store=au", nil)
+	assert.Exactly(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	rec = serveREST(t, h, "GET", "/stores/F@il", nil)
+	assert.Exactly(t, http.StatusBadRequest, rec.Code)
+
+	rec = serveREST(t, h, "GET", "/stores/zz", nil)
+	assert.Exactly(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), store.ErrStoreNotFound.Error())
+}
+
+func TestRESTHandlerIncludeAdmin(t *testing.T) {
+	h := getRESTTestHandler(t)
+
+	for _, url := range []string{"/websites/0", "/websites/admin", "/groups/0", "/stores/0", "/stores/admin"} {
+		rec := serveREST(t, h, "GET", url, nil)
+		assert.Exactly(t, http.StatusNotFound, rec.Code, url)
+	}
+
+	h.IncludeAdmin = true
+	var ws []store.RESTWebsite
+	serveREST(t, h, "GET", "/websites", &ws)
+	assert.Len(t, ws, 3)
+	var gs []store.RESTGroup
+	serveREST(t, h, "GET", "/groups", &gs)
+	assert.Len(t, gs, 4)
+	var ss []store.RESTStore
+	serveREST(t, h, "GET", "/stores", &ss)
+	assert.Len(t, ss, 7)
+
+	var s store.RESTStore
+	rec := serveREST(t, h, "GET", "/stores/admin", &s)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.Exactly(t, int64(0), s.ID)
+}
+
+func TestRESTHandlerErrors(t *testing.T) {
+	h := getRESTTestHandler(t)
+
+	rec := serveREST(t, h, "POST", "/stores", nil)
+	assert.Exactly(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Exactly(t, "GET, HEAD", rec.Header().Get("Allow"))
+
+	rec = serveREST(t, h, "GET", "/customers", nil)
+	assert.Exactly(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRESTHandlerCurrentStoreRedirect(t *testing.T) {
+	m := getAcceptLanguageManager(store.AcceptLanguageRedirect)
+	assert.NoError(t, m.Init(config.ScopeID(1), config.ScopeGroupID))
+	h := store.NewRESTHandler(m, config.ScopeGroupID)
+
+	req, err := http.NewRequest("GET", "http://cs.io/stores/current", nil)
+	assert.NoError(t, err)
+	req.Header.Set(store.HTTPHeaderAcceptLanguage, "de-DE,de;q=0.8")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Exactly(t, http.StatusFound, rec.Code)
+	assert.NotContains(t, rec.Body.String(), `"error"`)
+	assert.Contains(t, rec.Header().Get("Location"), "___store=de")
+}
+
+func TestRESTHandlerIncludeInactive(t *testing.T) {
+	m := getAcceptLanguageManager(store.AcceptLanguageDisabled)
+	assert.NoError(t, m.Init(config.ScopeID(1), config.ScopeStoreID))
+	h := store.NewRESTHandler(m, config.ScopeStoreID)
+
+	var ss []store.RESTStore
+	serveREST(t, h, "GET", "/stores", &ss)
+	assert.Len(t, ss, 5)
+	rec := serveREST(t, h, "GET", "/stores/ch", nil)
+	assert.Exactly(t, http.StatusNotFound, rec.Code)
+
+	h.IncludeInactive = true
+	serveREST(t, h, "GET", "/stores", &ss)
+	assert.Len(t, ss, 6)
+	var s store.RESTStore
+	rec = serveREST(t, h, "GET", "/stores/ch", &s)
+	assert.Exactly(t, http.StatusOK, rec.Code)
+	assert.False(t, s.IsActive)
+	assert.Exactly(t, "de-CH", s.Locale)
+}
@@ -28,17 +28,19 @@ type (
 	// This interface is used in the StoreManager
 	Storager interface {
 		// Website creates a new Website pointer from an ID or code including all of its
-		// groups and all related stores. It panics when the integrity is incorrect.
+		// groups and all related stores.
+		// Only panics for broken data which has not been loaded via ReInit(),
+		// ReInit() returns hard integrity violations as *IntegrityReport error.
 		// If ID and code are available then the non-empty code has precedence.
 		Website(config.ScopeIDer) (*Website, error)
 		// Websites creates a slice containing all pointers to Websites with its associated
-		// groups and stores. It panics when the integrity is incorrect.
+		// groups and stores. See Website() about panics.
 		Websites() (WebsiteSlice, error)
 		// Group creates a new Group which contains all related stores and its website.
 		// Only the argument ID can be used to get a specific Group.
 		Group(config.ScopeIDer) (*Group, error)
 		// Groups creates a slice containing all pointers to Groups with its associated
-		// stores and websites. It panics when the integrity is incorrect.
+		// stores and websites. See Website() about panics.
 		Groups() (GroupSlice, error)
 		// Store creates a new Store containing its group and its website.
 		// If ID and code are available then the non-empty code has precedence.
@@ -49,7 +51,10 @@ type (
 		// DefaultStoreView traverses through the websites to find the default website and gets
 		// the default group which has the default store id assigned to. Only one website can be the default one.
 		DefaultStoreView() (*Store, error)
-		// ReInit reloads the websites, groups and stores from the database.
+		// ReInit reloads the websites, groups and stores from the database and
+		// validates their integrity. Hard violations return an *IntegrityReport
+		// as error and the previous data will be kept, so the methods above
+		// cannot panic anymore.
 		ReInit(dbr.SessionRunner, ...csdb.DbrSelectCb) error
 	}
 
@@ -240,50 +245,59 @@ func (st *Storage) DefaultStoreView() (*Store, error) {
 }
 
 // ReInit reloads all websites, groups and stores concurrently from the database. If GOMAXPROCS
-// is set to > 1 then in parallel. Returns an error with location or nil. If an error occurs
-// then all internal slices will be reset.
+// is set to > 1 then in parallel. Returns an error with location or nil. After loading the
+// integrity gets checked and an *IntegrityReport containing only the hard violations will be
+// returned as error, see IntegrityReport.Hard(). In case of an error the previously loaded
+// data will be kept. Soft violations can be retrieved via Integrity().
 func (st *Storage) ReInit(dbrSess dbr.SessionRunner, cbs ...csdb.DbrSelectCb) error {
-	st.mu.Lock()
-	defer st.mu.Unlock()
+	var (
+		tws TableWebsiteSlice
+		tgs TableGroupSlice
+		tss TableStoreSlice
+	)
 
 	errc := make(chan error)
 	defer close(errc)
 	// not sure about those three go
 	go func() {
-		for i := range st.websites {
-			st.websites[i] = nil // I'm not quite sure if that is needed to clear the pointers
-		}
-		st.websites = nil
-		_, err := st.websites.Load(dbrSess, cbs...)
+		_, err := tws.Load(dbrSess, cbs...)
 		errc <- errgo.Mask(err)
 	}()
 
 	go func() {
-		for i := range st.groups {
-			st.groups[i] = nil // I'm not quite sure if that is needed to clear the pointers
-		}
-		st.groups = nil
-		_, err := st.groups.Load(dbrSess, cbs...)
+		_, err := tgs.Load(dbrSess, cbs...)
 		errc <- errgo.Mask(err)
 	}()
 
 	go func() {
-		for i := range st.stores {
-			st.stores[i] = nil // I'm not quite sure if that is needed to clear the pointers
-		}
-		st.stores = nil
-		_, err := st.stores.Load(dbrSess, cbs...)
+		_, err := tss.Load(dbrSess, cbs...)
 		errc <- errgo.Mask(err)
 	}()
 
+	var err error
 	for i := 0; i < 3; i++ {
-		if err := <-errc; err != nil {
-			// in case of error clear all
-			st.websites = nil
-			st.groups = nil
-			st.stores = nil
-			return err
+		if e := <-errc; e != nil && err == nil {
+			err = e // wait for all goroutines before returning
 		}
 	}
+	if err != nil {
+		return err
+	}
+
+	if r := ValidateIntegrity(tws, tgs, tss).Hard(); !r.OK() {
+		return r
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.websites, st.groups, st.stores = tws, tgs, tss
 	return nil
 }
+
+// Integrity validates the currently loaded websites, groups and stores.
+// @see ValidateIntegrity()
+func (st *Storage) Integrity() *IntegrityReport {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return ValidateIntegrity(st.websites, st.groups, st.stores)
+}
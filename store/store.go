@@ -20,10 +20,8 @@ import (
 	"net/url"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/corestoreio/csfw/config"
-	"github.com/corestoreio/csfw/directory"
 	"github.com/corestoreio/csfw/storage/csdb"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/utils"
@@ -57,6 +55,8 @@ type (
 		g *Group
 		// underlying raw data
 		s *TableStore
+		// lc caches the locale, time zone and formatters, see Locale()
+		lc localeCache
 	}
 	// StoreSlice a collection of pointers to the Store structs. StoreSlice has some nifty method receviers.
 	StoreSlice []*Store
@@ -156,6 +156,9 @@ func (s *Store) Path() string {
 }
 
 // BaseUrl returns the path from the URL or config where CoreStore is installed @todo
+// The placeholders {{base_url}}, {{unsecure_base_url}} and {{secure_base_url}} will be replaced.
+// Empty static or media URLs fall back to the web URL with the path pub/static/
+// or pub/media/. An empty link URL falls back to the web URL.
 // @see https://github.com/magento/magento2/blob/0.74.0-beta7/app/code/Magento/Store/Model/Store.php#L539
 func (s *Store) BaseURL(ut config.URLType, isSecure bool) string {
 	var url string
@@ -167,6 +170,12 @@ func (s *Store) BaseURL(ut config.URLType, isSecure bool) string {
 			p = PathSecureBaseURL
 		}
 		break
+	case config.URLTypeLink:
+		p = PathUnsecureBaseLinkURL
+		if isSecure {
+			p = PathSecureBaseLinkURL
+		}
+		break
 	case config.URLTypeStatic:
 		p = PathUnsecureBaseStaticURL
 		if isSecure {
@@ -186,11 +195,31 @@ func (s *Store) BaseURL(ut config.URLType, isSecure bool) string {
 
 	url = s.ConfigString(p)
 
+	if url == "" && ut != config.URLTypeWeb {
+		url = s.BaseURL(config.URLTypeWeb, isSecure)
+		switch ut {
+		case config.URLTypeStatic:
+			url += "pub/static/"
+		case config.URLTypeMedia:
+			url += "pub/media/"
+		}
+		return url
+	}
+
 	if strings.Contains(url, PlaceholderBaseURL) {
 		// @todo replace placeholder with \Magento\Framework\App\Request\Http::getDistroBaseUrl()
 		// getDistroBaseUrl will be generated from the $_SERVER variable,
 		url = strings.Replace(url, PlaceholderBaseURL, s.cr.GetString(config.Path(config.PathCSBaseURL)), 1)
 	}
+	// the unsecure web URL itself cannot contain the following placeholders.
+	if ut != config.URLTypeWeb || isSecure {
+		if strings.Contains(url, PlaceholderBaseURLUnSecure) {
+			url = strings.Replace(url, PlaceholderBaseURLUnSecure, s.BaseURL(config.URLTypeWeb, false), 1)
+		}
+		if ut != config.URLTypeWeb && strings.Contains(url, PlaceholderBaseURLSecure) {
+			url = strings.Replace(url, PlaceholderBaseURLSecure, s.BaseURL(config.URLTypeWeb, true), 1)
+		}
+	}
 	url = strings.TrimRight(url, "/") + "/"
 
 	return url
@@ -207,37 +236,10 @@ func (s *Store) ConfigString(path ...string) string {
 	return val
 }
 
-// NewCookie creates a new pre-configured cookie.
-// @todo create cookie manager to stick to the limits of http://www.ietf.org/rfc/rfc2109.txt page 15
-// @see http://browsercookielimits.squawky.net/
-func (s *Store) NewCookie() *http.Cookie {
-	return &http.Cookie{
-		Name:     CookieName,
-		Value:    "",
-		Path:     s.Path(),
-		Domain:   "",
-		Secure:   false,
-		HttpOnly: true,
-	}
-}
-
-// SetCookie adds a cookie which contains the store code and is valid for one year.
-func (s *Store) SetCookie(res http.ResponseWriter) {
-	if res != nil {
-		keks := s.NewCookie()
-		keks.Value = s.Data().Code.String
-		keks.Expires = time.Now().AddDate(1, 0, 0) // one year valid
-		http.SetCookie(res, keks)
-	}
-}
-
-// DeleteCookie deletes the store cookie
-func (s *Store) DeleteCookie(res http.ResponseWriter) {
-	if res != nil {
-		keks := s.NewCookie()
-		keks.Expires = time.Now().AddDate(-10, 0, 0)
-		http.SetCookie(res, keks)
-	}
+// ConfigBool returns a bool from the store scope and falls back to the
+// default scope if the value has not been set in the store scope.
+func (s *Store) ConfigBool(path ...string) bool {
+	return s.cr.GetBool(config.ScopeStore(s), config.Path(path...))
 }
 
 // AddClaim adds the store code to a JSON web token
@@ -250,21 +252,6 @@ func (s *Store) RootCategoryId() int64 {
 	return s.Group().Data().RootCategoryID
 }
 
-/*
-	Store Currency
-*/
-
-// AllowedCurrencies returns all installed currencies from global scope.
-func (s *Store) AllowedCurrencies() []string {
-	return strings.Split(s.cr.GetString(config.Path(directory.PathSystemCurrencyInstalled)), ",")
-}
-
-// CurrentCurrency @todo
-// @see app/code/Magento/Store/Model/Store.php::getCurrentCurrency
-func (s *Store) CurrentCurrency() *directory.Currency {
-	return nil
-}
-
 /*
 	Global functions
 */
@@ -20,6 +20,7 @@ import (
 
 	"github.com/corestoreio/csfw/config"
 	"github.com/corestoreio/csfw/storage/csdb"
+	"github.com/corestoreio/csfw/storage/csdb/csdbtest"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/store"
 	"github.com/corestoreio/csfw/utils"
@@ -341,3 +342,24 @@ func TestClaim(t *testing.T) {
 	sCode2 := store.GetCodeFromClaim(token2)
 	assert.Nil(t, sCode2)
 }
+
+func TestTableStoreSliceLoadMock(t *testing.T) {
+	db, mock := csdbtest.NewDB()
+	defer db.Close()
+	if err := mock.LoadDumpFile("../testData/magento-2.0-0.74.0-beta12.sql", store.TableCollection.Name(store.TableIndexStore)); err != nil {
+		t.Fatal(err)
+	}
+
+	var s store.TableStoreSlice
+	rows, err := s.Load(dbr.NewConnection(db, nil).NewSession(nil))
+	assert.NoError(t, err)
+	assert.True(t, rows > 1)
+	assert.Len(t, s, rows)
+	admin, err := s.FindByCode("admin")
+	assert.NoError(t, err)
+	assert.True(t, admin.IsDefault())
+
+	stmts := mock.Statements()
+	assert.Len(t, stmts, 1)
+	assert.Contains(t, stmts[0].SQL, "ORDER BY CASE WHEN main_table.store_id = 0 THEN 0 ELSE 1 END ASC")
+}
@@ -0,0 +1,203 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/juju/errgo"
+)
+
+type (
+	// Alternate is an entry of a store or group switcher. It contains the
+	// target store view and the URL of the equivalent page in that store view.
+	Alternate struct {
+		// Store the target store view
+		Store *Store
+		// Group is only set for entries returned by AlternateGroups().
+		Group *Group
+		// URL absolute URL of the current page in the target store view.
+		URL string
+		// HrefLang BCP 47 language tag of the target store view for
+		// <link rel="alternate" hreflang="..."/>. Empty if the locale is invalid.
+		HrefLang string
+		// IsCurrent true if the entry points to the current store or group.
+		IsCurrent bool
+	}
+	// AlternateSlice a collection of switcher entries.
+	AlternateSlice []Alternate
+)
+
+// Current returns the entry marked as current or false.
+func (as AlternateSlice) Current() (Alternate, bool) {
+	for _, a := range as {
+		if a.IsCurrent {
+			return a, true
+		}
+	}
+	return Alternate{}, false
+}
+
+// HrefLangs returns the hreflang values as keys and the URLs as values for
+// the entries which have a valid locale. Suitable for SEO link tags.
+func (as AlternateSlice) HrefLangs() map[string]string {
+	ret := make(map[string]string, len(as))
+	for _, a := range as {
+		if a.HrefLang != "" {
+			ret[a.HrefLang] = a.URL
+		}
+	}
+	return ret
+}
+
+// SwitchURL returns the absolute URL of the current page in the target store
+// view. The link prefix of the current store (base URL and store code) gets
+// removed from the request path and the remainder appended to the link URL of
+// the target. If the target does not use the store code in the URL the GET
+// parameter ___store will be added. The request can be nil.
+func (s *Store) SwitchURL(req *http.Request, target *Store) string {
+	var path string
+	var query url.Values
+	if req != nil && req.URL != nil {
+		path = req.URL.Path
+		if u, err := url.Parse(s.URL(req, config.URLTypeLink, "", nil)); err == nil {
+			// compare without trailing slash so that /de matches the base path /de/
+			// but /debug does not match /de
+			prefix := strings.TrimRight(u.Path, "/")
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				path = strings.TrimLeft(path[len(prefix):], "/")
+			}
+		}
+		query = req.URL.Query()
+		query.Del(HTTPRequestParamStore)
+	}
+	if false == target.ConfigBool(PathStoreInURL) {
+		if query == nil {
+			query = make(url.Values)
+		}
+		query.Set(HTTPRequestParamStore, target.Data().Code.String)
+	}
+	return target.URL(req, config.URLTypeLink, path, query)
+}
+
+// newAlternate creates a switcher entry for the target store.
+func (s *Store) newAlternate(req *http.Request, target *Store) Alternate {
+	a := Alternate{
+		Store:     target,
+		URL:       s.SwitchURL(req, target),
+		IsCurrent: target.Data().StoreID == s.Data().StoreID,
+	}
+	if t, err := target.Locale(); err == nil {
+		a.HrefLang = t.String()
+	}
+	return a
+}
+
+// AlternateStores returns all active store views of this group including the
+// current store for a language switcher. Each entry points to the equivalent
+// page of the current request.
+func (g *Group) AlternateStores(req *http.Request, current *Store) (AlternateSlice, error) {
+	if current == nil {
+		return nil, ErrStoreNotFound
+	}
+	ss, err := g.Stores()
+	if err != nil {
+		return nil, err
+	}
+	var as AlternateSlice
+	for _, s := range ss.Filter(isSwitchable) {
+		as = append(as, current.newAlternate(req, s))
+	}
+	return as, nil
+}
+
+// AlternateGroups returns for all groups of this website the default store
+// view for a group switcher. If the default store view of a group is inactive
+// the first active store view will be used. Groups without an active store
+// view will be skipped.
+func (w *Website) AlternateGroups(req *http.Request, current *Store) (AlternateSlice, error) {
+	if current == nil {
+		return nil, ErrStoreNotFound
+	}
+	gs, err := w.Groups()
+	if err != nil {
+		return nil, err
+	}
+	var as AlternateSlice
+	for _, g := range gs {
+		s := switchTarget(g)
+		if s == nil {
+			continue
+		}
+		a := current.newAlternate(req, s)
+		a.Group = g
+		a.IsCurrent = g.Data().GroupID == current.Data().GroupID
+		as = append(as, a)
+	}
+	return as, nil
+}
+
+// AlternateStores returns the store views of the group of the current store.
+// If current is nil the appStore will be used. See Group.AlternateStores().
+func (sm *Manager) AlternateStores(req *http.Request, current *Store) (AlternateSlice, error) {
+	current, err := sm.switchCurrent(current)
+	if err != nil {
+		return nil, err
+	}
+	as, err := current.Group().AlternateStores(req, current)
+	return as, errgo.Mask(err)
+}
+
+// AlternateGroups returns the groups of the website of the current store.
+// If current is nil the appStore will be used. See Website.AlternateGroups().
+func (sm *Manager) AlternateGroups(req *http.Request, current *Store) (AlternateSlice, error) {
+	current, err := sm.switchCurrent(current)
+	if err != nil {
+		return nil, err
+	}
+	as, err := current.Website().AlternateGroups(req, current)
+	return as, errgo.Mask(err)
+}
+
+// switchCurrent returns current or the appStore
+func (sm *Manager) switchCurrent(current *Store) (*Store, error) {
+	if current != nil {
+		return current, nil
+	}
+	return sm.Store()
+}
+
+// switchTarget returns the store view which represents a group in a switcher.
+func switchTarget(g *Group) *Store {
+	if ds, err := g.DefaultStore(); err == nil && isSwitchable(ds) {
+		return ds
+	}
+	ss, err := g.Stores()
+	if err != nil {
+		return nil
+	}
+	if ss = ss.Filter(isSwitchable); len(ss) > 0 {
+		return ss[0]
+	}
+	return nil
+}
+
+// isSwitchable returns true for active non-admin store views.
+func isSwitchable(s *Store) bool {
+	return s.Data().IsActive && s.Data().StoreID != DefaultStoreID
+}
@@ -0,0 +1,121 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreSwitchURL(t *testing.T) {
+	tests := []struct {
+		useStore bool
+		reqURL   string
+		from, to string
+		wantURL  string
+	}{
+		{false, "http://cs.io/catalog/product/view?id=1", "de", "at", "http://cs.io/catalog/product/view?___store=at&id=1"},
+		{false, "http://cs.io/catalog?___store=de", "de", "at", "http://cs.io/catalog?___store=at"},
+		{true, "http://cs.io/de/catalog/product/view?id=1", "de", "at", "http://cs.io/at/catalog/product/view?id=1"},
+		{true, "http://cs.io/de/", "de", "uk", "http://cs.io/uk/"},
+		{true, "http://cs.io/de", "de", "uk", "http://cs.io/uk/"},
+		{true, "http://cs.io/debug/info", "de", "at", "http://cs.io/at/debug/info"},
+		{true, "http://cs.io/other/path", "de", "at", "http://cs.io/at/other/path"},
+	}
+	for _, test := range tests {
+		sm := getLocaleManager(store.AcceptLanguageDisabled, test.useStore)
+		from, err := sm.Store(config.ScopeCode(test.from))
+		assert.NoError(t, err)
+		to, err := sm.Store(config.ScopeCode(test.to))
+		assert.NoError(t, err)
+		req, err := http.NewRequest("GET", test.reqURL, nil)
+		assert.NoError(t, err)
+		assert.Exactly(t, test.wantURL, from.SwitchURL(req, to), "Test: %#v", test)
+	}
+
+	sm := getLocaleManager(store.AcceptLanguageDisabled, true)
+	de, err := sm.Store(config.ScopeCode("de"))
+	assert.NoError(t, err)
+	assert.Exactly(t, "http://cs.io/de/", de.SwitchURL(nil, de))
+}
+
+func TestManagerAlternateStores(t *testing.T) {
+	sm := getLocaleManager(store.AcceptLanguageDisabled, true)
+	assert.NoError(t, sm.Init(config.ScopeCode("at"), config.ScopeStoreID))
+
+	req, err := http.NewRequest("GET", "http://cs.io/at/checkout/cart?x=y", nil)
+	assert.NoError(t, err)
+
+	as, err := sm.AlternateStores(req, nil)
+	assert.NoError(t, err)
+	// ch is inactive
+	assert.Len(t, as, 2)
+	assert.Exactly(t, map[string]string{
+		"de-DE": "http://cs.io/de/checkout/cart?x=y",
+		"de-AT": "http://cs.io/at/checkout/cart?x=y",
+	}, as.HrefLangs())
+
+	cur, ok := as.Current()
+	assert.True(t, ok)
+	assert.Exactly(t, "at", cur.Store.Data().Code.String)
+	assert.Nil(t, cur.Group)
+
+	nz, err := sm.Store(config.ScopeCode("nz"))
+	assert.NoError(t, err)
+	as, err = sm.AlternateStores(req, nz)
+	assert.NoError(t, err)
+	assert.Len(t, as, 2)
+	// request path does not belong to nz so the whole path will be kept
+	assert.Exactly(t, "http://cs.io/au/at/checkout/cart?x=y", as[0].URL)
+	_, ok = as.Current()
+	assert.True(t, ok)
+}
+
+func TestManagerAlternateGroups(t *testing.T) {
+	sm := getLocaleManager(store.AcceptLanguageDisabled, false)
+	assert.NoError(t, sm.Init(config.ScopeCode("de"), config.ScopeStoreID))
+
+	req, err := http.NewRequest("GET", "http://cs.io/customer/account", nil)
+	assert.NoError(t, err)
+
+	as, err := sm.AlternateGroups(req, nil)
+	assert.NoError(t, err)
+	assert.Len(t, as, 2)
+	for _, a := range as {
+		switch a.Group.Data().GroupID {
+		case 1:
+			assert.True(t, a.IsCurrent)
+			assert.Exactly(t, "at", a.Store.Data().Code.String) // default store of group 1
+			assert.Exactly(t, "de-AT", a.HrefLang)
+			assert.Exactly(t, "http://cs.io/customer/account?___store=at", a.URL)
+		case 2:
+			assert.False(t, a.IsCurrent)
+			assert.Exactly(t, "en-GB", a.HrefLang)
+			assert.Exactly(t, "http://cs.io/customer/account?___store=uk", a.URL)
+		default:
+			t.Errorf("Unexpected group %#v", a.Group.Data())
+		}
+	}
+
+	g, err := sm.Group(config.ScopeID(1))
+	assert.NoError(t, err)
+	as, err = g.AlternateStores(req, nil)
+	assert.Nil(t, as)
+	assert.EqualError(t, err, store.ErrStoreNotFound.Error())
+}
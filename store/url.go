@@ -0,0 +1,103 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/corestoreio/csfw/config"
+)
+
+const (
+	// HTTPHeaderForwardedProto set by proxies and load balancers, value https or http.
+	HTTPHeaderForwardedProto = "X-Forwarded-Proto"
+	// HTTPHeaderFrontEndHTTPS set by Microsoft proxies, value on or off.
+	HTTPHeaderFrontEndHTTPS = "Front-End-Https"
+)
+
+// IsSecureRequest checks if the request has been sent via HTTPS. Detects TLS,
+// the headers X-Forwarded-Proto and Front-End-Https and the header configured
+// in web/secure/offloader_header.
+// @see \Magento\Framework\App\Request\Http::isSecure()
+func (s *Store) IsSecureRequest(req *http.Request) bool {
+	if req == nil {
+		return false
+	}
+	if req.TLS != nil {
+		return true
+	}
+	if strings.EqualFold(req.Header.Get(HTTPHeaderForwardedProto), "https") {
+		return true
+	}
+	if strings.EqualFold(req.Header.Get(HTTPHeaderFrontEndHTTPS), "on") {
+		return true
+	}
+	if h := s.ConfigString(PathSecureOffloaderHeader); h != "" {
+		v := req.Header.Get(h)
+		return strings.EqualFold(v, "https") || strings.EqualFold(v, "on") || v == "1"
+	}
+	return false
+}
+
+// IsFrontURLSecure returns true if the secure URLs should be used in the frontend.
+// Reads the value from web/secure/use_in_frontend.
+func (s *Store) IsFrontURLSecure() bool {
+	return s.ConfigBool(PathSecureInFrontend)
+}
+
+// IsCurrentlySecure returns true if the secure base URLs must be used for the
+// current request. The request must be secure and the configuration value
+// web/secure/use_in_frontend must be enabled.
+func (s *Store) IsCurrentlySecure(req *http.Request) bool {
+	return s.IsFrontURLSecure() && s.IsSecureRequest(req)
+}
+
+// URL builds an absolute URL for a path. The request can be nil and is used to
+// detect if the secure base URLs must be used, see IsCurrentlySecure().
+// URL types:
+//   - config.URLTypeLink adds the store code as first path segment if web/url/use_store is enabled.
+//   - config.URLTypeStatic adds the segment version<n> if dev/static/sign is enabled and
+//     web/corestore/static_version contains a version.
+//   - config.URLTypeWeb and config.URLTypeMedia append only the path.
+//
+// The query can be nil. It gets merged with an already existing query in the path.
+// Panics if the URL type is not supported, see BaseURL().
+func (s *Store) URL(req *http.Request, ut config.URLType, path string, query url.Values) string {
+	u := s.BaseURL(ut, s.IsCurrentlySecure(req))
+
+	switch ut {
+	case config.URLTypeLink:
+		if s.ConfigBool(PathStoreInURL) && s.Data().Code.String != "" {
+			u += s.Data().Code.String + "/"
+		}
+	case config.URLTypeStatic:
+		if v := s.ConfigString(PathStaticVersion); v != "" && s.ConfigBool(PathStaticSign) {
+			u += "version" + v + "/"
+		}
+	}
+
+	u += strings.TrimLeft(path, "/")
+	if len(query) > 0 {
+		if strings.Contains(u, "?") {
+			u += "&"
+		} else {
+			u += "?"
+		}
+		u += query.Encode()
+	}
+	return u
+}
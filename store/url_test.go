@@ -0,0 +1,132 @@
+// Copyright 2015, Cyrill @ Schumacher.fm and the CoreStore contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package store_test
+
+import (
+	"crypto/tls"
+	"database/sql"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/corestoreio/csfw/config"
+	"github.com/corestoreio/csfw/storage/dbr"
+	"github.com/corestoreio/csfw/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// newURLTestStore creates the store "de" with ID 1. The map contains the
+// store scope paths and their values.
+func newURLTestStore(s map[string]string, b map[string]bool) *store.Store {
+	return store.NewStore(
+		&store.TableStore{StoreID: 1, Code: dbr.NullString{NullString: sql.NullString{String: "de", Valid: true}}, WebsiteID: 1, GroupID: 1, Name: "Germany", SortOrder: 10, IsActive: true},
+		&store.TableWebsite{WebsiteID: 1, Code: dbr.NullString{NullString: sql.NullString{String: "euro", Valid: true}}, Name: dbr.NullString{NullString: sql.NullString{String: "Europe", Valid: true}}, SortOrder: 0, DefaultGroupID: 1, IsDefault: dbr.NullBool{NullBool: sql.NullBool{Bool: true, Valid: true}}},
+		&store.TableGroup{GroupID: 1, WebsiteID: 1, Name: "DACH Group", RootCategoryID: 2, DefaultStoreID: 1},
+		store.SetStoreConfig(config.NewMockReader(
+			config.MockString(func(path string) string {
+				return s[path]
+			}),
+			config.MockBool(func(path string) bool {
+				return b[path]
+			}),
+		)),
+	)
+}
+
+func TestStoreIsSecureRequest(t *testing.T) {
+	s := newURLTestStore(map[string]string{
+		config.MockPathScopeStore(1, store.PathSecureOffloaderHeader): "Ssl-Offloaded",
+	}, nil)
+
+	tests := []struct {
+		header map[string]string
+		tls    bool
+		want   bool
+	}{
+		{nil, false, false},
+		{nil, true, true},
+		{map[string]string{store.HTTPHeaderForwardedProto: "https"}, false, true},
+		{map[string]string{store.HTTPHeaderForwardedProto: "http"}, false, false},
+		{map[string]string{store.HTTPHeaderFrontEndHTTPS: "On"}, false, true},
+		{map[string]string{store.HTTPHeaderFrontEndHTTPS: "off"}, false, false},
+		{map[string]string{"Ssl-Offloaded": "1"}, false, true},
+		{map[string]string{"Ssl-Offloaded": "no"}, false, false},
+		{map[string]string{"X-Ssl-Offloaded": "1"}, false, false},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest("GET", "http://corestore.io/", nil)
+		assert.NoError(t, err)
+		for k, v := range test.header {
+			req.Header.Set(k, v)
+		}
+		if test.tls {
+			req.TLS = &tls.ConnectionState{}
+		}
+		assert.Exactly(t, test.want, s.IsSecureRequest(req), "Test: %#v", test)
+	}
+	assert.False(t, s.IsSecureRequest(nil))
+}
+
+func TestStoreURL(t *testing.T) {
+	strs := map[string]string{
+		config.MockPathScopeStore(1, store.PathUnsecureBaseURL):       "http://corestore.io/",
+		config.MockPathScopeStore(1, store.PathSecureBaseURL):         "https://corestore.io/",
+		config.MockPathScopeStore(1, store.PathUnsecureBaseLinkURL):   store.PlaceholderBaseURLUnSecure,
+		config.MockPathScopeStore(1, store.PathSecureBaseLinkURL):     store.PlaceholderBaseURLSecure,
+		config.MockPathScopeStore(1, store.PathUnsecureBaseMediaURL):  "http://cdn.corestore.io/media",
+		config.MockPathScopeStore(1, store.PathSecureBaseStaticURL):   "{{secure_base_url}}assets",
+		config.MockPathScopeStore(1, store.PathStaticVersion):         "1445787034",
+		config.MockPathScopeStore(1, store.PathSecureOffloaderHeader): "",
+	}
+	bools := map[string]bool{
+		config.MockPathScopeStore(1, store.PathStoreInURL):       true,
+		config.MockPathScopeStore(1, store.PathStaticSign):       true,
+		config.MockPathScopeStore(1, store.PathSecureInFrontend): true,
+	}
+	s := newURLTestStore(strs, bools)
+
+	httpReq, _ := http.NewRequest("GET", "http://corestore.io/", nil)
+	httpsReq, _ := http.NewRequest("GET", "http://corestore.io/", nil)
+	httpsReq.Header.Set(store.HTTPHeaderForwardedProto, "https")
+
+	tests := []struct {
+		req   *http.Request
+		ut    config.URLType
+		path  string
+		query url.Values
+		want  string
+	}{
+		{nil, config.URLTypeWeb, "", nil, "http://corestore.io/"},
+		{httpsReq, config.URLTypeWeb, "/robots.txt", nil, "https://corestore.io/robots.txt"},
+		{httpReq, config.URLTypeLink, "checkout/cart", nil, "http://corestore.io/de/checkout/cart"},
+		{httpsReq, config.URLTypeLink, "checkout/cart", url.Values{"id": []string{"3"}}, "https://corestore.io/de/checkout/cart?id=3"},
+		{httpsReq, config.URLTypeLink, "search?q=gopher", url.Values{"p": []string{"2"}}, "https://corestore.io/de/search?q=gopher&p=2"},
+		{httpReq, config.URLTypeStatic, "css/styles.css", nil, "http://corestore.io/pub/static/version1445787034/css/styles.css"},
+		{httpsReq, config.URLTypeStatic, "css/styles.css", nil, "https://corestore.io/assets/version1445787034/css/styles.css"},
+		{httpsReq, config.URLTypeMedia, "catalog/product/g/o/gopher.jpg", nil, "https://corestore.io/pub/media/catalog/product/g/o/gopher.jpg"},
+		{httpReq, config.URLTypeMedia, "catalog/product/g/o/gopher.jpg", nil, "http://cdn.corestore.io/media/catalog/product/g/o/gopher.jpg"},
+	}
+	for _, test := range tests {
+		assert.Exactly(t, test.want, s.URL(test.req, test.ut, test.path, test.query), "Test: %#v", test)
+	}
+
+	// secure URLs disabled in the frontend
+	bools[config.MockPathScopeStore(1, store.PathSecureInFrontend)] = false
+	bools[config.MockPathScopeStore(1, store.PathStoreInURL)] = false
+	bools[config.MockPathScopeStore(1, store.PathStaticSign)] = false
+	assert.False(t, s.IsCurrentlySecure(httpsReq))
+	assert.Exactly(t, "http://corestore.io/checkout/cart", s.URL(httpsReq, config.URLTypeLink, "/checkout/cart", nil))
+	assert.Exactly(t, "http://corestore.io/pub/static/js/app.js", s.URL(httpsReq, config.URLTypeStatic, "js/app.js", nil))
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/corestoreio/csfw/storage/csdb"
 	"github.com/corestoreio/csfw/storage/dbr"
 	"github.com/corestoreio/csfw/utils"
+	"github.com/juju/errgo"
 	"golang.org/x/text/language"
 )
 
@@ -39,6 +40,8 @@ type (
 	Website struct {
 		cr config.Reader
 		w  *TableWebsite
+		// rates used for currency conversion. If nil directory.DefaultRates applies.
+		rates *directory.Rates
 
 		// groups contains a slice to all groups associated to one website. This slice can be nil.
 		groups GroupSlice
@@ -72,6 +75,12 @@ func SetWebsiteConfig(cr config.Reader) WebsiteOption {
 	return func(w *Website) { w.cr = cr }
 }
 
+// SetWebsiteCurrencyRates sets the currency conversion rates used by the
+// base currency of the Website. Default rates are directory.DefaultRates.
+func SetWebsiteCurrencyRates(r *directory.Rates) WebsiteOption {
+	return func(w *Website) { w.rates = r }
+}
+
 // NewWebsite returns a new pointer to a Website.
 func NewWebsite(tw *TableWebsite, opts ...WebsiteOption) *Website {
 	if tw == nil {
@@ -182,9 +191,14 @@ func (w *Website) BaseCurrencyCode() (language.Currency, error) {
 	return language.ParseCurrency(c)
 }
 
-// @todo
-func (w *Website) BaseCurrency() directory.Currency {
-	return directory.Currency{}
+// BaseCurrency returns the base currency of the website including the
+// conversion rates. All prices are stored in this currency.
+func (w *Website) BaseCurrency() (directory.Currency, error) {
+	c, err := w.BaseCurrencyCode()
+	if err != nil {
+		return directory.Currency{}, errgo.Mask(err)
+	}
+	return directory.NewCurrency(c, w.rates), nil
 }
 
 /*